@@ -25,6 +25,11 @@ func main() {
 	application := app.New(log, cfg)
 
 	go application.GRPCAppServer.MustRun()
+	go application.OutboxDispatcher.Run()
+	go application.Reconciler.Run()
+	go application.InviteSweeper.Run()
+	go application.JWTKeys.Run()
+	go application.UserCache.Run()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -35,6 +40,24 @@ func main() {
 	application.GRPCAppServer.Stop()
 
 	log.Info("grpc server shut down")
+
+	application.Reconciler.Stop()
+
+	log.Info("reconciler shut down")
+
+	application.InviteSweeper.Stop()
+
+	log.Info("invite sweeper shut down")
+
+	application.OutboxDispatcher.Stop()
+
+	log.Info("outbox dispatcher shut down")
+
+	application.JWTKeys.Stop()
+
+	log.Info("jwks refresher shut down")
+
+	application.UserCache.Stop()
 }
 
 func setupLogger(env string) *slog.Logger {
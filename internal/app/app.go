@@ -7,16 +7,29 @@ import (
 	grpcclient "github.com/Stanislau-Senkevich/GRPC_Family/internal/client/sso/grpc"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
 	jwtmanager "github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/memory"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/mongodb"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/postgres"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/family"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/familyleader"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/familysearch"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/invite"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/outbox"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/reconciler"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/revocation"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/sso"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services/succession"
 	"log/slog"
 )
 
 type App struct {
-	GRPCAppServer *grpcapp.App
+	GRPCAppServer    *grpcapp.App
+	JWTKeys          *jwtmanager.KeySet
+	OutboxDispatcher *outbox.Dispatcher
+	Reconciler       *reconciler.ReconcilerService
+	InviteSweeper    *invite.Sweeper
+	UserCache        *sso.CachedSSO
 }
 
 // New creates a new instance of the application with the provided configuration and dependencies.
@@ -26,13 +39,30 @@ func New(
 ) *App {
 	log.Info("starting initialize app")
 
-	repo, err := mongodb.InitMongoRepository(&cfg.Mongo, log)
+	repo, err := initRepository(log, cfg)
 	if err != nil {
 		panic(fmt.Errorf("failed to initialize repository: %w", err))
 	}
-	log.Info("repository initialized")
+	log.Info("repository initialized", slog.String("storage", cfg.Storage))
 
-	jwtManager := jwtmanager.New([]byte(cfg.SigningKey))
+	revocationRepo, err := initRevocationRepository(log, cfg, repo)
+	if err != nil {
+		panic(fmt.Errorf("failed to initialize revocation store: %w", err))
+	}
+	log.Info("revocation store initialized", slog.String("backend", cfg.Revocation.Backend))
+
+	jwtKeys, err := jwtmanager.NewKeySet(log,
+		cfg.JWT.PublicKeyPath, cfg.JWT.JWKSSource, cfg.JWT.JWKSRefreshInterval)
+	if err != nil {
+		panic(fmt.Errorf("failed to load jwt keys: %w", err))
+	}
+
+	jwtManager := jwtmanager.New([]byte(cfg.SigningKey), jwtKeys, jwtmanager.Validation{
+		Issuers:       cfg.JWT.Issuers,
+		Audiences:     cfg.JWT.Audiences,
+		RequireExpiry: cfg.JWT.RequireExpiry,
+		Leeway:        cfg.JWT.Leeway,
+	})
 	log.Info("jwt-manager initialized")
 
 	ssoClient, err := grpcclient.New(
@@ -45,41 +75,120 @@ func New(
 	}
 	log.Info("sso client initialized")
 
-	familyService := family.New(log, repo, jwtManager)
+	ssoService := sso.New(ssoClient,
+		cfg.ClientsConfig.AdminEmail, cfg.ClientsConfig.AdminPassword,
+		cfg.ClientsConfig.SSO.Concurrency)
+	log.Info("sso service initialized")
+
+	cachedSSO := sso.NewCached(log, ssoService, &cfg.UserCache)
+	log.Info("sso user cache initialized")
+
+	successionPlanner := succession.New(log, cachedSSO)
+
+	familyService := family.New(log, repo, repo, repo, repo, successionPlanner)
 	log.Info("family service initialized")
 
-	leaderService := familyleader.New(log, repo, repo, jwtManager)
+	leaderService := familyleader.New(log, repo, repo, repo, repo, repo, successionPlanner)
 	log.Info("family leader service initialized")
 
-	inviteService := invite.New(log, repo, repo, jwtManager)
+	inviteService := invite.New(log, repo, repo, repo, repo, repo, repo, cachedSSO, &cfg.Invite)
 	log.Info("invite service initialized")
 
-	ssoService := sso.New(ssoClient, jwtManager, cfg.ClientsConfig.AdminEmail, cfg.ClientsConfig.AdminPassword)
-	log.Info("sso service initialized")
-
-	accessibleRoles := map[string][]string{
-		"/family.Family/CreateFamily":       {"user", "admin"},
-		"/family.Family/LeaveFamily":        {"user", "admin"},
-		"/family.Family/GetFamilyInfo":      {"user", "admin"},
-		"/family.Invite/GetInvites":         {"user", "admin"},
-		"/family.Invite/SendInvite":         {"user", "admin"},
-		"/family.Invite/AcceptInvite":       {"user", "admin"},
-		"/family.Invite/DenyInvite":         {"user", "admin"},
-		"family.Invite/DeleteUserInvites":   {"admin"},
-		"/family.FamilyLeader/RemoveUser":   {"user", "admin"},
-		"/family.FamilyLeader/DeleteFamily": {"user", "admin"},
+	sweepInterval := cfg.Invite.SweepInterval
+	if cfg.Storage == config.StorageMongo {
+		// expired invites are removed by the TTL index
+		sweepInterval = 0
 	}
 
-	grpcApp := grpcapp.New(
+	inviteSweeper := invite.NewSweeper(log, repo, sweepInterval)
+	log.Info("invite sweeper initialized")
+
+	outboxDispatcher := outbox.New(log, repo, ssoService, &cfg.Outbox)
+	log.Info("outbox dispatcher initialized")
+
+	reconcilerService := reconciler.New(log, repo, repo, repo, repo, ssoService, &cfg.Reconciler)
+	log.Info("reconciler initialized")
+
+	revocationService := revocation.New(log, revocationRepo, &cfg.Revocation)
+	log.Info("revocation service initialized")
+
+	familySearchService := familysearch.New(log, repo)
+	log.Info("family search service initialized")
+
+	grpcApp, err := grpcapp.New(
 		log, &cfg.GRPC,
 		familyService, leaderService,
-		inviteService, ssoService,
-		accessibleRoles, jwtManager,
+		inviteService, inviteService, reconcilerService,
+		cachedSSO, revocationService, familySearchService, cachedSSO,
+		cfg.AuthPolicy, jwtManager, revocationRepo,
 	)
+	if err != nil {
+		panic(fmt.Errorf("failed to initialize grpc-server: %w", err))
+	}
 
 	log.Info("grpc-server initialized")
 
 	return &App{
-		GRPCAppServer: grpcApp,
+		GRPCAppServer:    grpcApp,
+		JWTKeys:          jwtKeys,
+		OutboxDispatcher: outboxDispatcher,
+		Reconciler:       reconcilerService,
+		InviteSweeper:    inviteSweeper,
+		UserCache:        cachedSSO,
+	}
+}
+
+// initRepository creates the storage backend selected by the configuration.
+func initRepository(log *slog.Logger, cfg *config.Config) (repository.Repository, error) {
+	switch cfg.Storage {
+	case config.StorageMongo:
+		repo, err := mongodb.InitMongoRepository(&cfg.Mongo, &cfg.IDGenerator, log)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case config.StorageMemory:
+		repo, err := memory.InitMemoryRepository(&cfg.IDGenerator, log)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case config.StoragePostgres:
+		if cfg.IDGenerator.Kind != config.IDSequence {
+			log.Warn("postgres storage always uses its own sequences, id_generator is ignored",
+				slog.String("id_generator", cfg.IDGenerator.Kind))
+		}
+		repo, err := postgres.InitPostgresRepository(&cfg.Postgres, log)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unknown storage: %q", cfg.Storage)
+	}
+}
+
+// initRevocationRepository creates the token revocation store selected by the configuration.
+// The mongo store shares the client of the main repository when it is backed by MongoDB,
+// otherwise it gets a connection of its own.
+func initRevocationRepository(
+	log *slog.Logger,
+	cfg *config.Config,
+	repo repository.Repository,
+) (repository.RevocationRepository, error) {
+	switch cfg.Revocation.Backend {
+	case config.RevocationMemory:
+		return memory.NewRevocationStore(), nil
+	case config.RevocationMongo:
+		if mongoRepo, ok := repo.(*mongodb.MongoRepository); ok {
+			return mongoRepo, nil
+		}
+		mongoRepo, err := mongodb.InitRevocationStore(&cfg.Mongo, log)
+		if err != nil {
+			return nil, err
+		}
+		return mongoRepo, nil
+	default:
+		return nil, fmt.Errorf("unknown revocation backend: %q", cfg.Revocation.Backend)
 	}
 }
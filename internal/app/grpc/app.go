@@ -3,10 +3,12 @@ package grpcapp
 import (
 	"fmt"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/grpc/admin"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/grpc/family"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/grpc/familyleader"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/grpc/invite"
 	jwtmanager "github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
 	"google.golang.org/grpc"
 	"log/slog"
@@ -20,6 +22,7 @@ type App struct {
 }
 
 // New creates a new instance of the application with the specified dependencies and configurations.
+// It returns an error if the authorization policy is invalid or does not cover exactly the registered methods.
 func New(
 	log *slog.Logger,
 	gRPCConfig *config.GRPCConfig,
@@ -27,11 +30,24 @@ func New(
 	familyService services.Family,
 	leaderService services.FamilyLeader,
 	inviteService services.Invite,
+	joinCodeService services.JoinCode,
+	reconciler services.Reconciler,
+	userCache services.UserCache,
+	revocation services.Revocation,
+	familySearch services.FamilySearch,
 	sso services.SSO,
-	accessibleRoles map[string][]string,
+	authPolicy []config.MethodRule,
 	jwtManager *jwtmanager.Manager,
-) *App {
-	interceptor := NewJWTInterceptor(jwtManager, accessibleRoles)
+	revocations repository.RevocationRepository,
+) (*App, error) {
+	const op = "grpcapp.New"
+
+	policy, err := NewPolicy(authPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	interceptor := NewJWTInterceptor(jwtManager, policy, revocations)
 
 	gRPCServer := grpc.NewServer(
 		grpc.UnaryInterceptor(interceptor.Unary()),
@@ -40,10 +56,15 @@ func New(
 	)
 
 	family.Register(gRPCServer, log, familyService, sso)
-	invite.Register(gRPCServer, log, inviteService, sso)
-	familyleader.Register(gRPCServer, log, leaderService, sso)
+	invite.Register(gRPCServer, log, inviteService, joinCodeService, sso)
+	familyleader.Register(gRPCServer, log, leaderService)
+	admin.Register(gRPCServer, log, reconciler, userCache, revocation, familySearch)
+
+	if err = policy.Validate(gRPCServer.GetServiceInfo()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	return &App{log, gRPCServer, gRPCConfig}
+	return &App{log, gRPCServer, gRPCConfig}, nil
 }
 
 func (a *App) MustRun() {
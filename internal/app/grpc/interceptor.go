@@ -2,64 +2,124 @@ package grpcapp
 
 import (
 	"context"
+	"errors"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"slices"
 	"strings"
 )
 
 type JWTInterceptor struct {
-	manager         *jwt.Manager
-	accessibleRoles map[string][]string
+	manager     *jwt.Manager
+	policy      *Policy
+	revocations repository.RevocationRepository
 }
 
-// NewJWTInterceptor creates a new instance of JWTInterceptor with the provided JWT manager and accessibleRoles map.
-// The JWTInterceptor is used as a gRPC server interceptor to validate JWT tokens and enforce role-based access control.
+// NewJWTInterceptor creates a new instance of JWTInterceptor with the provided JWT manager, authorization policy
+// and revocation store. The JWTInterceptor is used as a gRPC server interceptor to validate JWT tokens,
+// reject revoked ones and enforce role-based access control.
 func NewJWTInterceptor(
 	manager *jwt.Manager,
-	accessibleRoles map[string][]string,
+	policy *Policy,
+	revocations repository.RevocationRepository,
 ) *JWTInterceptor {
-	return &JWTInterceptor{manager: manager, accessibleRoles: accessibleRoles}
+	return &JWTInterceptor{manager: manager, policy: policy, revocations: revocations}
 }
 
-// authorize checks whether the user is authorized to access a specific gRPC method based on JWT token claims and accessible roles.
-func (i *JWTInterceptor) authorize(ctx context.Context, method string) error {
-	_, ok := i.accessibleRoles[method]
+// authorize checks whether the user is authorized to access a specific gRPC method based on JWT token claims
+// and the policy. Methods without a rule are denied. The token is parsed only here: on success the returned
+// context carries the caller's principal, which services read with jwt.PrincipalFromContext.
+func (i *JWTInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	rule, ok := i.policy.Rule(method)
 	if !ok {
-		// everyone can access
-		return nil
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+
+	if rule.Public {
+		return ctx, nil
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
 	}
 
 	values := md["authorization"]
 	if len(values) == 0 {
-		return status.Errorf(codes.Unauthenticated, grpcerror.ErrNoToken.Error())
+		return nil, status.Errorf(codes.Unauthenticated, grpcerror.ErrNoToken.Error())
 	}
 
-	if parts := strings.Fields(values[0]); len(parts) < 2 {
-		return status.Error(codes.Unauthenticated, grpcerror.ErrInvalidToken.Error())
+	parts := strings.Fields(values[0])
+	if len(parts) < 2 {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrInvalidToken.Error())
 	}
 
-	accessToken := strings.Fields(values[0])[1]
-	claims, err := i.manager.ParseToken(accessToken)
+	claims, err := i.manager.ParseToken(ctx, parts[1])
 	if err != nil {
-		return status.Errorf(codes.Unauthenticated, grpcerror.ErrInvalidToken.Error())
+		return nil, tokenError(err)
 	}
 
-	for _, role := range i.accessibleRoles[method] {
-		if role == claims["role"] {
-			return nil
-		}
+	jti, userID, issuedAt := jwt.TokenIdentity(claims)
+
+	revoked, err := i.revocations.IsTokenRevoked(ctx, jti, userID, issuedAt)
+	if err != nil {
+		// fail closed: a token can't be accepted while its revocation status is unknown
+		return nil, status.Error(codes.Unavailable, "failed to check token revocation")
+	}
+	if revoked {
+		return nil, tokenError(grpcerror.ErrTokenRevoked)
+	}
+
+	principal := jwt.PrincipalFromClaims(claims)
+
+	if slices.Contains(rule.Roles, principal.Role) {
+		return jwt.ContextWithPrincipal(ctx, principal), nil
+	}
+
+	return nil, status.Errorf(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+}
+
+// tokenError converts a token validation error into a gRPC status. Expired and not yet valid
+// tokens are reported as unauthenticated, tokens minted for another issuer or audience as
+// permission denied, and anything else as a malformed token. The reason is also attached
+// as ErrorInfo details, so clients can tell these cases apart without parsing messages.
+func tokenError(err error) error {
+	var (
+		code   codes.Code
+		reason string
+		cause  error
+	)
+
+	switch {
+	case errors.Is(err, grpcerror.ErrTokenExpired):
+		code, reason, cause = codes.Unauthenticated, "TOKEN_EXPIRED", grpcerror.ErrTokenExpired
+	case errors.Is(err, grpcerror.ErrTokenNotValidYet):
+		code, reason, cause = codes.Unauthenticated, "TOKEN_NOT_VALID_YET", grpcerror.ErrTokenNotValidYet
+	case errors.Is(err, grpcerror.ErrTokenRevoked):
+		code, reason, cause = codes.Unauthenticated, "TOKEN_REVOKED", grpcerror.ErrTokenRevoked
+	case errors.Is(err, grpcerror.ErrTokenIssuer):
+		code, reason, cause = codes.PermissionDenied, "TOKEN_WRONG_ISSUER", grpcerror.ErrTokenIssuer
+	case errors.Is(err, grpcerror.ErrTokenAudience):
+		code, reason, cause = codes.PermissionDenied, "TOKEN_WRONG_AUDIENCE", grpcerror.ErrTokenAudience
+	default:
+		code, reason, cause = codes.Unauthenticated, "TOKEN_MALFORMED", grpcerror.ErrInvalidToken
+	}
+
+	st, detailsErr := status.New(code, cause.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: "family",
+	})
+	if detailsErr != nil {
+		return status.Error(code, cause.Error())
 	}
 
-	return status.Errorf(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	return st.Err()
 }
 
 // Unary returns a gRPC UnaryServerInterceptor that performs authorization checks before allowing the execution
@@ -71,7 +131,7 @@ func (i *JWTInterceptor) Unary() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		err := i.authorize(ctx, info.FullMethod)
+		ctx, err := i.authorize(ctx, info.FullMethod)
 		if err != nil {
 			return nil, err
 		}
@@ -89,11 +149,21 @@ func (i *JWTInterceptor) Stream() grpc.StreamServerInterceptor {
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		err := i.authorize(stream.Context(), info.FullMethod)
+		ctx, err := i.authorize(stream.Context(), info.FullMethod)
 		if err != nil {
 			return err
 		}
 
-		return handler(srv, stream)
+		return handler(srv, &principalStream{ServerStream: stream, ctx: ctx})
 	}
 }
+
+// principalStream overrides the context of a server stream with the one carrying the principal.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
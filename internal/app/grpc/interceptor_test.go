@@ -0,0 +1,86 @@
+package grpcapp
+
+import (
+	"errors"
+	"fmt"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestTokenError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name:       "expired",
+			err:        fmt.Errorf("%w: expired at 2024-01-01T00:00:00Z", grpcerror.ErrTokenExpired),
+			wantCode:   codes.Unauthenticated,
+			wantReason: "TOKEN_EXPIRED",
+		},
+		{
+			name:       "not valid yet",
+			err:        fmt.Errorf("%w: valid from 2024-01-01T00:00:00Z", grpcerror.ErrTokenNotValidYet),
+			wantCode:   codes.Unauthenticated,
+			wantReason: "TOKEN_NOT_VALID_YET",
+		},
+		{
+			name:       "revoked",
+			err:        grpcerror.ErrTokenRevoked,
+			wantCode:   codes.Unauthenticated,
+			wantReason: "TOKEN_REVOKED",
+		},
+		{
+			name:       "wrong issuer",
+			err:        fmt.Errorf("%w: %q", grpcerror.ErrTokenIssuer, "other"),
+			wantCode:   codes.PermissionDenied,
+			wantReason: "TOKEN_WRONG_ISSUER",
+		},
+		{
+			name:       "wrong audience",
+			err:        fmt.Errorf("%w: %q", grpcerror.ErrTokenAudience, []string{"billing"}),
+			wantCode:   codes.PermissionDenied,
+			wantReason: "TOKEN_WRONG_AUDIENCE",
+		},
+		{
+			name:       "missing exp",
+			err:        fmt.Errorf("%w: exp was not found", grpcerror.ErrInvalidToken),
+			wantCode:   codes.Unauthenticated,
+			wantReason: "TOKEN_MALFORMED",
+		},
+		{
+			name:       "bad signature",
+			err:        fmt.Errorf("%w: %w", grpcerror.ErrInvalidToken, errors.New("signature is invalid")),
+			wantCode:   codes.Unauthenticated,
+			wantReason: "TOKEN_MALFORMED",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(tokenError(tt.err))
+
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %v, want %v", st.Code(), tt.wantCode)
+			}
+
+			var reason string
+			for _, detail := range st.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					reason = info.GetReason()
+				}
+			}
+
+			if reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package grpcapp
+
+import (
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"google.golang.org/grpc"
+	"slices"
+	"strings"
+)
+
+// Policy is a default-deny authorization policy: a method is accessible only if it has a rule,
+// either marking it public or listing the roles allowed to call it.
+type Policy struct {
+	rules map[string]config.MethodRule
+}
+
+// NewPolicy builds the policy from method rules. Every rule must name a full method
+// ("/package.Service/Method") once and must be either public or allow at least one role.
+func NewPolicy(rules []config.MethodRule) (*Policy, error) {
+	p := &Policy{rules: make(map[string]config.MethodRule, len(rules))}
+
+	for _, rule := range rules {
+		if !isFullMethod(rule.Method) {
+			return nil, fmt.Errorf("invalid method %q: expected /package.Service/Method", rule.Method)
+		}
+
+		if _, ok := p.rules[rule.Method]; ok {
+			return nil, fmt.Errorf("duplicate rule for method %q", rule.Method)
+		}
+
+		if rule.Public == (len(rule.Roles) > 0) {
+			return nil, fmt.Errorf("rule for method %q must be either public or list roles", rule.Method)
+		}
+
+		p.rules[rule.Method] = rule
+	}
+
+	return p, nil
+}
+
+// Rule returns the rule of the method. Methods without a rule must be denied.
+func (p *Policy) Rule(method string) (config.MethodRule, bool) {
+	rule, ok := p.rules[method]
+	return rule, ok
+}
+
+// Validate checks that every method registered on the server has a rule and that every rule
+// refers to a registered method, so neither a new RPC nor a misspelled rule goes unnoticed.
+func (p *Policy) Validate(services map[string]grpc.ServiceInfo) error {
+	var missing, unknown []string
+
+	registered := make(map[string]struct{})
+
+	for service, info := range services {
+		for _, m := range info.Methods {
+			method := "/" + service + "/" + m.Name
+			registered[method] = struct{}{}
+
+			if _, ok := p.rules[method]; !ok {
+				missing = append(missing, method)
+			}
+		}
+	}
+
+	for method := range p.rules {
+		if _, ok := registered[method]; !ok {
+			unknown = append(unknown, method)
+		}
+	}
+
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+	slices.Sort(unknown)
+
+	return fmt.Errorf("auth policy does not match registered methods: without rules %v, not registered %v",
+		missing, unknown)
+}
+
+func isFullMethod(method string) bool {
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+
+	return strings.HasPrefix(method, "/") && ok &&
+		strings.Contains(service, ".") && name != "" && !strings.Contains(name, "/")
+}
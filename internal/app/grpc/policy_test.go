@@ -0,0 +1,184 @@
+package grpcapp
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"github.com/ilyakaznacheev/cleanenv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+)
+
+// serviceInfo describes the services as grpc.Server.GetServiceInfo does once they are registered.
+func serviceInfo(descs ...*grpc.ServiceDesc) map[string]grpc.ServiceInfo {
+	services := make(map[string]grpc.ServiceInfo, len(descs))
+
+	for _, desc := range descs {
+		info := grpc.ServiceInfo{}
+		for _, m := range desc.Methods {
+			info.Methods = append(info.Methods, grpc.MethodInfo{Name: m.MethodName})
+		}
+		for _, s := range desc.Streams {
+			info.Methods = append(info.Methods, grpc.MethodInfo{Name: s.StreamName})
+		}
+		services[desc.ServiceName] = info
+	}
+
+	return services
+}
+
+func TestPolicyValidate(t *testing.T) {
+	services := map[string]grpc.ServiceInfo{
+		"family.Invite": {Methods: []grpc.MethodInfo{{Name: "SendInvite"}, {Name: "DenyInvite"}}},
+	}
+
+	tests := []struct {
+		name    string
+		rules   []config.MethodRule
+		wantErr string
+	}{
+		{
+			name: "every method has a rule",
+			rules: []config.MethodRule{
+				{Method: "/family.Invite/SendInvite", Roles: []string{"user"}},
+				{Method: "/family.Invite/DenyInvite", Public: true},
+			},
+		},
+		{
+			name: "registered method without rule",
+			rules: []config.MethodRule{
+				{Method: "/family.Invite/SendInvite", Roles: []string{"user"}},
+			},
+			wantErr: "/family.Invite/DenyInvite",
+		},
+		{
+			name: "rule for unknown method",
+			rules: []config.MethodRule{
+				{Method: "/family.Invite/SendInvite", Roles: []string{"user"}},
+				{Method: "/family.Invite/DenyInvite", Roles: []string{"user"}},
+				{Method: "/family.Invite/DeleteUserInvites", Roles: []string{"admin"}},
+			},
+			wantErr: "/family.Invite/DeleteUserInvites",
+		},
+		{
+			name: "rule for misspelled method",
+			rules: []config.MethodRule{
+				{Method: "/family.Invite/SendInvite", Roles: []string{"user"}},
+				{Method: "/family.Invite/DenyInvites", Roles: []string{"user"}},
+			},
+			wantErr: "/family.Invite/DenyInvites",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.rules)
+			if err != nil {
+				t.Fatalf("NewPolicy() error = %v", err)
+			}
+
+			err = policy.Validate(services)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Validate() error = %v, want an error naming %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPolicyRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []config.MethodRule
+	}{
+		{
+			name:  "method without service",
+			rules: []config.MethodRule{{Method: "SendInvite", Roles: []string{"user"}}},
+		},
+		{
+			name: "duplicate method",
+			rules: []config.MethodRule{
+				{Method: "/family.Invite/SendInvite", Roles: []string{"user"}},
+				{Method: "/family.Invite/SendInvite", Roles: []string{"admin"}},
+			},
+		},
+		{
+			name:  "neither public nor roles",
+			rules: []config.MethodRule{{Method: "/family.Invite/SendInvite"}},
+		},
+		{
+			name:  "both public and roles",
+			rules: []config.MethodRule{{Method: "/family.Invite/SendInvite", Public: true, Roles: []string{"user"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.rules); err == nil {
+				t.Fatal("NewPolicy() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// TestConfigPolicies checks that the shipped configurations have a rule for every method
+// of the registered services and no rules for methods which don't exist.
+func TestConfigPolicies(t *testing.T) {
+	services := serviceInfo(
+		&famv1.Family_ServiceDesc,
+		&famv1.Invite_ServiceDesc,
+		&famv1.FamilyLeader_ServiceDesc,
+		&famv1.Admin_ServiceDesc,
+	)
+
+	for _, path := range []string{"../../../config/dev.yaml", "../../../config/local.yaml"} {
+		path := path
+
+		t.Run(path, func(t *testing.T) {
+			var cfg config.Config
+
+			if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+
+			policy, err := NewPolicy(cfg.AuthPolicy)
+			if err != nil {
+				t.Fatalf("NewPolicy() error = %v", err)
+			}
+
+			if err = policy.Validate(services); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDeniesMethodWithoutRule(t *testing.T) {
+	policy, err := NewPolicy([]config.MethodRule{
+		{Method: "/family.Invite/SendInvite", Public: true},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	interceptor := NewJWTInterceptor(nil, policy, nil)
+
+	if _, err = interceptor.authorize(context.Background(), "/family.Invite/SendInvite"); err != nil {
+		t.Fatalf("authorize() of a public method error = %v, want nil", err)
+	}
+
+	_, err = interceptor.authorize(context.Background(), "/family.Invite/DenyInvite")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("authorize() of a method without rule error = %v, want %v", err, codes.PermissionDenied)
+	}
+}
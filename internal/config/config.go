@@ -10,16 +10,48 @@ import (
 )
 
 const (
-	FamilyCollection   = "family"
-	InviteCollection   = "invite"
-	SequenceCollection = "sequence"
+	FamilyCollection        = "family"
+	InviteCollection        = "invite"
+	SequenceCollection      = "sequence"
+	OutboxCollection        = "outbox"
+	RevocationCollection    = "revocation"
+	JoinCodeCollection      = "join_code"
+	ContactInviteCollection = "contact_invite"
+	FormerMemberCollection  = "former_member"
+)
+
+const (
+	RevocationMemory = "memory"
+	RevocationMongo  = "mongo"
+)
+
+const (
+	IDSequence  = "sequence"
+	IDSnowflake = "snowflake"
+	IDULID      = "ulid"
+)
+
+const (
+	StorageMongo    = "mongo"
+	StorageMemory   = "memory"
+	StoragePostgres = "postgres"
 )
 
 type Config struct {
-	Env           string         `yaml:"env" env-default:"local"`
-	Mongo         MongoConfig    `yaml:"mongo_config"`
-	GRPC          GRPCConfig     `yaml:"grpc"`
-	ClientsConfig *ClientsConfig `yaml:"clients_config"`
+	Env           string            `yaml:"env" env-default:"local"`
+	Storage       string            `yaml:"storage" env-default:"mongo"`
+	Mongo         MongoConfig       `yaml:"mongo_config"`
+	Postgres      PostgresConfig    `yaml:"postgres_config"`
+	IDGenerator   IDGeneratorConfig `yaml:"id_generator"`
+	GRPC          GRPCConfig        `yaml:"grpc"`
+	ClientsConfig *ClientsConfig    `yaml:"clients_config"`
+	Outbox        OutboxConfig      `yaml:"outbox"`
+	Reconciler    ReconcilerConfig  `yaml:"reconciler"`
+	Invite        InviteConfig      `yaml:"invite"`
+	UserCache     UserCacheConfig   `yaml:"user_cache"`
+	JWT           JWTConfig         `yaml:"jwt"`
+	Revocation    RevocationConfig  `yaml:"revocation"`
+	AuthPolicy    []MethodRule      `yaml:"auth_policy"`
 	SigningKey    string
 }
 
@@ -31,15 +63,84 @@ type MongoConfig struct {
 	Collections      map[string]string `yaml:"collections"`
 }
 
+type PostgresConfig struct {
+	User             string
+	Password         string
+	ConnectionString string `yaml:"conn_string"`
+}
+
+// IDGeneratorConfig selects how the Mongo and in-memory storages generate IDs: a per-collection
+// sequence kept in the storage, Snowflake IDs requiring a distinct NodeID on every instance,
+// or ULID-style IDs. Outbox events are always numbered by a sequence, as they are delivered in that order.
+// PostgreSQL always uses its own sequences.
+type IDGeneratorConfig struct {
+	Kind   string `yaml:"kind" env-default:"sequence"`
+	NodeID int64  `yaml:"node_id"`
+}
+
 type GRPCConfig struct {
 	Port    int           `yaml:"port"`
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`
+	MinBackoff   time.Duration `yaml:"min_backoff" env-default:"1s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env-default:"5m"`
+	Lease        time.Duration `yaml:"lease" env-default:"30s"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"20"`
+}
+
+type ReconcilerConfig struct {
+	Interval    time.Duration `yaml:"interval" env-default:"1h"`
+	DryRun      bool          `yaml:"dry_run"`
+	PageSize    int           `yaml:"page_size" env-default:"100"`
+	Concurrency int           `yaml:"concurrency" env-default:"8"`
+}
+
+// InviteConfig sets the lifetime of new invites and how often expired invites are removed
+// by the sweeper. The sweeper is not run with MongoDB, which removes them by a TTL index.
+type InviteConfig struct {
+	TTL           time.Duration `yaml:"ttl" env-default:"168h"`
+	SweepInterval time.Duration `yaml:"sweep_interval" env-default:"10m"`
+}
+
+type UserCacheConfig struct {
+	Size          int           `yaml:"size" env-default:"10000"`
+	TTL           time.Duration `yaml:"ttl" env-default:"5m"`
+	NegativeTTL   time.Duration `yaml:"negative_ttl" env-default:"30s"`
+	StatsInterval time.Duration `yaml:"stats_interval" env-default:"1m"`
+}
+
+type JWTConfig struct {
+	PublicKeyPath       string        `yaml:"public_key_path"`
+	JWKSSource          string        `yaml:"jwks_source"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" env-default:"10m"`
+	Issuers             []string      `yaml:"issuers"`
+	Audiences           []string      `yaml:"audiences"`
+	RequireExpiry       bool          `yaml:"require_expiry" env-default:"true"`
+	Leeway              time.Duration `yaml:"leeway" env-default:"30s"`
+}
+
+type RevocationConfig struct {
+	Backend  string        `yaml:"backend" env-default:"memory"`
+	TokenTTL time.Duration `yaml:"token_ttl" env-default:"24h"`
+}
+
+// MethodRule grants access to a gRPC method: either to everyone, including unauthenticated
+// callers, if Public is set, or to callers with one of Roles.
+type MethodRule struct {
+	Method string   `yaml:"method"`
+	Roles  []string `yaml:"roles"`
+	Public bool     `yaml:"public"`
+}
+
 type Client struct {
 	Address      string        `yaml:"address"`
 	Timeout      time.Duration `yaml:"timeout"`
 	RetriesCount int           `yaml:"retries_count"`
+	Concurrency  int           `yaml:"concurrency" env-default:"8"`
 }
 
 type ClientsConfig struct {
@@ -92,6 +193,8 @@ func parseEnv(cfg *Config) error {
 
 	cfg.Mongo.User = viper.GetString("mongo_user")
 	cfg.Mongo.Password = viper.GetString("mongo_password")
+	cfg.Postgres.User = viper.GetString("postgres_user")
+	cfg.Postgres.Password = viper.GetString("postgres_password")
 	cfg.SigningKey = viper.GetString("signing_key")
 	cfg.ClientsConfig.AdminEmail = viper.GetString("admin_email")
 	cfg.ClientsConfig.AdminPassword = viper.GetString("admin_password")
@@ -112,6 +215,14 @@ func BindEnv() error {
 		return fmt.Errorf("failed to set up mongo_password: %w", err)
 	}
 
+	if err := viper.BindEnv("postgres_user"); err != nil {
+		return fmt.Errorf("failed to set up postgres_user: %w", err)
+	}
+
+	if err := viper.BindEnv("postgres_password"); err != nil {
+		return fmt.Errorf("failed to set up postgres_password: %w", err)
+	}
+
 	if err := viper.BindEnv("hash_salt"); err != nil {
 		return fmt.Errorf("failed to set up hash_salt: %w", err)
 	}
@@ -0,0 +1,31 @@
+package models
+
+import famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+
+// CacheStats describes the effectiveness of a cache since the service start.
+type CacheStats struct {
+	Hits         int64
+	NegativeHits int64
+	Misses       int64
+	Size         int
+}
+
+// HitRatio returns the share of lookups served from the cache, including negative entries.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.NegativeHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits+s.NegativeHits) / float64(total)
+}
+
+func ConvertToCacheStatsResponse(stats CacheStats) *famv1.GetUserCacheStatsResponse {
+	return &famv1.GetUserCacheStatsResponse{
+		Hits:         stats.Hits,
+		NegativeHits: stats.NegativeHits,
+		Misses:       stats.Misses,
+		Size:         int64(stats.Size),
+		HitRatio:     stats.HitRatio(),
+	}
+}
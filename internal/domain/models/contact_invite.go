@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"strings"
+	"time"
+)
+
+const (
+	emailContactPrefix = "email:"
+	phoneContactPrefix = "phone:"
+)
+
+// Contact is the email or the phone number of a person who may not be registered yet.
+// Exactly one of the fields is set.
+type Contact struct {
+	Email       string
+	PhoneNumber string
+}
+
+// Validate checks that exactly one of the fields is set and that it looks like an email
+// or a phone number in the international format, from 7 to 15 digits.
+func (c Contact) Validate() error {
+	email, phone := normalizeEmail(c.Email), normalizePhone(c.PhoneNumber)
+
+	switch {
+	case (email == "") == (phone == ""):
+		return fmt.Errorf("%w: either email or phone number must be set", grpcerror.ErrInvalidContact)
+	case email != "" && !isEmail(email):
+		return fmt.Errorf("%w: malformed email", grpcerror.ErrInvalidContact)
+	case phone != "" && (len(phone) < 7 || len(phone) > 15):
+		return fmt.Errorf("%w: malformed phone number", grpcerror.ErrInvalidContact)
+	default:
+		return nil
+	}
+}
+
+// Key returns the normalized contact pending invites are stored by, e.g. "email:alice@example.com"
+// or "phone:375291234567". Emails are compared case-insensitively and phone numbers by their digits only.
+func (c Contact) Key() string {
+	if email := normalizeEmail(c.Email); email != "" {
+		return emailContactPrefix + email
+	}
+
+	return phoneContactPrefix + normalizePhone(c.PhoneNumber)
+}
+
+// ContactFromKey returns the contact the key was made of by Key.
+func ContactFromKey(key string) Contact {
+	if email, ok := strings.CutPrefix(key, emailContactPrefix); ok {
+		return Contact{Email: email}
+	}
+
+	return Contact{PhoneNumber: strings.TrimPrefix(key, phoneContactPrefix)}
+}
+
+// ContactKeys returns the keys of the contacts of the user pending invites can be addressed to.
+func ContactKeys(user *User) []string {
+	var keys []string
+
+	if email := normalizeEmail(user.Email); email != "" {
+		keys = append(keys, emailContactPrefix+email)
+	}
+
+	if phone := normalizePhone(user.PhoneNumber); phone != "" {
+		keys = append(keys, phoneContactPrefix+phone)
+	}
+
+	return keys
+}
+
+// ContactInvite is a pending invitation to a family addressed to a contact rather than to a user.
+// It is turned into a regular Invite of the user with that contact when they get their invites.
+type ContactInvite struct {
+	ID        int64     `bson:"contact_invite_id"`
+	FamilyID  int64     `bson:"family_id"`
+	Contact   string    `bson:"contact"`
+	InviterID int64     `bson:"inviter_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizePhone keeps only the digits of the phone number, dropping the leading plus,
+// spaces, dashes and brackets it is often written with.
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+// isEmail is a deliberately loose check: a single @ with something on both sides and a dot in the domain.
+func isEmail(email string) bool {
+	local, domain, ok := strings.Cut(email, "@")
+
+	return ok && local != "" && strings.Contains(domain, ".") &&
+		!strings.ContainsAny(domain, "@ ") && !strings.ContainsRune(local, ' ')
+}
@@ -1,7 +1,208 @@
 package models
 
+import (
+	"fmt"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/url"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxNameLength        = 64
+	maxDescriptionLength = 1024
+	maxAvatarURLLength   = 2048
+)
+
 type Family struct {
-	ID           int64   `bson:"family_id"`
-	LeaderUserID int64   `bson:"leader_id"`
-	MembersID    []int64 `bson:"members"`
+	ID               int64            `bson:"family_id"`
+	LeaderUserID     int64            `bson:"leader_id"`
+	MembersID        []int64          `bson:"members"`
+	Members          []Member         `bson:"member_details"`
+	SuccessionPolicy SuccessionPolicy `bson:"succession_policy,omitempty"`
+	Name             string           `bson:"name"`
+	Description      string           `bson:"description"`
+	AvatarURL        string           `bson:"avatar_url"`
+	CreatedAt        time.Time        `bson:"created_at"`
+	UpdatedAt        time.Time        `bson:"updated_at"`
+	// MemberCount is the length of MembersID, stored to filter and sort families by their size.
+	MemberCount int `bson:"member_count"`
+}
+
+// FamilyProfile is the human-readable data of a family set on creation.
+type FamilyProfile struct {
+	Name        string
+	Description string
+	AvatarURL   string
+}
+
+// FamilyUpdate is a partial update of a family profile: nil fields are left unchanged.
+type FamilyUpdate struct {
+	Name        *string
+	Description *string
+	AvatarURL   *string
+}
+
+// SuccessionPolicy defines who becomes the leader of the family when the leader leaves it.
+type SuccessionPolicy string
+
+const (
+	// LongestTenuredSuccession passes the leadership to the member who joined the family first.
+	LongestTenuredSuccession SuccessionPolicy = "longest_tenured"
+	// OldestMemberSuccession passes the leadership to the member with the oldest SSO account.
+	OldestMemberSuccession SuccessionPolicy = "oldest_member"
+	// RequireTransferSuccession forbids the leader to leave until the leadership is transferred explicitly.
+	RequireTransferSuccession SuccessionPolicy = "require_transfer"
+)
+
+// IsValid reports whether the policy is one of the known succession policies.
+func (p SuccessionPolicy) IsValid() bool {
+	switch p {
+	case LongestTenuredSuccession, OldestMemberSuccession, RequireTransferSuccession:
+		return true
+	default:
+		return false
+	}
+}
+
+// Succession returns the succession policy of the family, which is LongestTenuredSuccession if it is not set.
+func (f *Family) Succession() SuccessionPolicy {
+	if f.SuccessionPolicy == "" {
+		return LongestTenuredSuccession
+	}
+
+	return f.SuccessionPolicy
+}
+
+// Member is a user's membership in a family. The leader of the family always has the owner role.
+// JoinedAt is zero for members who joined before it was recorded.
+type Member struct {
+	UserID   int64      `bson:"user_id"`
+	Role     FamilyRole `bson:"role"`
+	JoinedAt time.Time  `bson:"joined_at,omitempty"`
+}
+
+// FamilySummary is a family as seen by one of its members.
+type FamilySummary struct {
+	Family Family
+	Role   FamilyRole
+}
+
+// RoleOf returns the role of the user in the family. The second value is false if the user is not a member.
+func (f *Family) RoleOf(userID int64) (FamilyRole, bool) {
+	for _, member := range f.Members {
+		if member.UserID == userID {
+			return member.Role, true
+		}
+	}
+
+	return "", false
+}
+
+// Validate checks the lengths of the profile fields and that the avatar URL, if set, is an http(s) URL.
+func (p FamilyProfile) Validate() error {
+	return validateProfile(p.Name, p.Description, p.AvatarURL)
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u FamilyUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Description == nil && u.AvatarURL == nil
+}
+
+// Validate checks the fields being updated in the same way as FamilyProfile.Validate.
+func (u FamilyUpdate) Validate() error {
+	var name, description, avatarURL string
+
+	if u.Name != nil {
+		name = *u.Name
+	}
+	if u.Description != nil {
+		description = *u.Description
+	}
+	if u.AvatarURL != nil {
+		avatarURL = *u.AvatarURL
+	}
+
+	return validateProfile(name, description, avatarURL)
+}
+
+// Apply sets the updated fields on the family.
+func (u FamilyUpdate) Apply(family *Family) {
+	if u.Name != nil {
+		family.Name = *u.Name
+	}
+	if u.Description != nil {
+		family.Description = *u.Description
+	}
+	if u.AvatarURL != nil {
+		family.AvatarURL = *u.AvatarURL
+	}
+}
+
+func validateProfile(name, description, avatarURL string) error {
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return fmt.Errorf("%w: name is longer than %d characters", grpcerror.ErrInvalidProfile, maxNameLength)
+	}
+
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description is longer than %d characters",
+			grpcerror.ErrInvalidProfile, maxDescriptionLength)
+	}
+
+	if avatarURL == "" {
+		return nil
+	}
+
+	if len(avatarURL) > maxAvatarURLLength {
+		return fmt.Errorf("%w: avatar url is longer than %d characters",
+			grpcerror.ErrInvalidProfile, maxAvatarURLLength)
+	}
+
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: avatar url must be an absolute http(s) url", grpcerror.ErrInvalidProfile)
+	}
+
+	return nil
+}
+
+func ConvertToFamilyModel(family *Family) *famv1.FamilyModel {
+	return &famv1.FamilyModel{
+		FamilyId:         family.ID,
+		LeaderId:         family.LeaderUserID,
+		Name:             family.Name,
+		Description:      family.Description,
+		AvatarUrl:        family.AvatarURL,
+		SuccessionPolicy: string(family.Succession()),
+		CreatedAt:        timestampOrNil(family.CreatedAt),
+		UpdatedAt:        timestampOrNil(family.UpdatedAt),
+		MemberCount:      int64(len(family.MembersID)),
+	}
+}
+
+func ConvertToFamilySummary(summary *FamilySummary) *famv1.FamilySummary {
+	return &famv1.FamilySummary{
+		Family:      ConvertToFamilyModel(&summary.Family),
+		Role:        string(summary.Role),
+		MemberCount: int64(len(summary.Family.MembersID)),
+	}
+}
+
+func ConvertToMemberModel(member *Member) *famv1.MemberModel {
+	return &famv1.MemberModel{
+		UserId:   member.UserID,
+		Role:     string(member.Role),
+		JoinedAt: timestampOrNil(member.JoinedAt),
+	}
+}
+
+// timestampOrNil converts t into a protobuf timestamp, leaving unknown (zero) times unset.
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
 }
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"slices"
+	"time"
+)
+
+// FamilySort is a field families can be sorted by. Ties are always broken by the family ID.
+type FamilySort string
+
+const (
+	SortByID          FamilySort = "family_id"
+	SortByCreatedAt   FamilySort = "created_at"
+	SortByMemberCount FamilySort = "member_count"
+)
+
+// IsValid reports whether families can be sorted by s.
+func (s FamilySort) IsValid() bool {
+	switch s {
+	case SortByID, SortByCreatedAt, SortByMemberCount:
+		return true
+	default:
+		return false
+	}
+}
+
+// FamilyFilter selects and orders families in admin listings. Zero fields do not restrict the result.
+type FamilyFilter struct {
+	LeaderID      int64
+	MemberID      int64
+	MinMembers    int
+	MaxMembers    int
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	SortBy        FamilySort
+	Descending    bool
+}
+
+// Validate checks the sort field and that the ranges of the filter are not empty.
+func (f FamilyFilter) Validate() error {
+	if !f.SortBy.IsValid() {
+		return fmt.Errorf("%w: unknown sort field %q", grpcerror.ErrInvalidFilter, f.SortBy)
+	}
+
+	if f.MinMembers < 0 || f.MaxMembers < 0 {
+		return fmt.Errorf("%w: member count bounds must not be negative", grpcerror.ErrInvalidFilter)
+	}
+
+	if f.MaxMembers > 0 && f.MinMembers > f.MaxMembers {
+		return fmt.Errorf("%w: min_members is greater than max_members", grpcerror.ErrInvalidFilter)
+	}
+
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		return fmt.Errorf("%w: created_after is not before created_before", grpcerror.ErrInvalidFilter)
+	}
+
+	return nil
+}
+
+// Matches reports whether the family passes the filter.
+func (f FamilyFilter) Matches(family *Family) bool {
+	count := len(family.MembersID)
+
+	switch {
+	case f.LeaderID != 0 && family.LeaderUserID != f.LeaderID:
+		return false
+	case f.MemberID != 0 && !slices.Contains(family.MembersID, f.MemberID):
+		return false
+	case count < f.MinMembers:
+		return false
+	case f.MaxMembers > 0 && count > f.MaxMembers:
+		return false
+	case !f.CreatedAfter.IsZero() && family.CreatedAt.Before(f.CreatedAfter):
+		return false
+	case !f.CreatedBefore.IsZero() && !family.CreatedAt.Before(f.CreatedBefore):
+		return false
+	default:
+		return true
+	}
+}
+
+// SortKey returns the value of the family's field the families are sorted by.
+// Creation times are represented in Unix microseconds, the precision all storages keep.
+// Families created before the creation time was recorded have the key of the zero time.
+func (f *Family) SortKey(sort FamilySort) int64 {
+	switch sort {
+	case SortByCreatedAt:
+		return f.CreatedAt.UnixMicro()
+	case SortByMemberCount:
+		return int64(len(f.MembersID))
+	default:
+		return f.ID
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// FormerMember is a user who left a family or whose family was deleted. It is kept until the reconciler
+// has checked the user's family list in the SSO service, so lists still referencing the family are found
+// even if the user is not a member of any family anymore.
+type FormerMember struct {
+	UserID   int64     `bson:"user_id"`
+	FamilyID int64     `bson:"family_id"`
+	LeftAt   time.Time `bson:"left_at"`
+}
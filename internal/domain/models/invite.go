@@ -1,17 +1,48 @@
 package models
 
-import famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+import (
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"time"
+)
 
+// Invite is an invitation of a user to a family. ExpiresAt is zero for invites sent
+// before expiration was introduced, which never expire, and InviterID is zero for invites
+// sent before inviters were recorded.
 type Invite struct {
-	ID       int64 `bson:"invite_id"`
-	FamilyID int64 `bson:"family_id"`
-	UserID   int64 `bson:"user_id"`
+	ID        int64     `bson:"invite_id"`
+	FamilyID  int64     `bson:"family_id"`
+	UserID    int64     `bson:"user_id"`
+	InviterID int64     `bson:"inviter_id,omitempty"`
+	CreatedAt time.Time `bson:"created_at,omitempty"`
+	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+}
+
+// IsExpired reports whether the invite can no longer be accepted at the moment now.
+func (i *Invite) IsExpired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
 }
 
 func ConvertToInviteModel(invite *Invite) *famv1.InviteModel {
 	return &famv1.InviteModel{
-		InviteId: invite.ID,
-		FamilyId: invite.FamilyID,
-		UserId:   invite.UserID,
+		InviteId:  invite.ID,
+		FamilyId:  invite.FamilyID,
+		UserId:    invite.UserID,
+		InviterId: invite.InviterID,
+		CreatedAt: timestampOrNil(invite.CreatedAt),
+		ExpiresAt: timestampOrNil(invite.ExpiresAt),
+	}
+}
+
+func ConvertToPendingInviteModel(invite *ContactInvite) *famv1.PendingInviteModel {
+	contact := ContactFromKey(invite.Contact)
+
+	return &famv1.PendingInviteModel{
+		PendingInviteId: invite.ID,
+		FamilyId:        invite.FamilyID,
+		Email:           contact.Email,
+		PhoneNumber:     contact.PhoneNumber,
+		InviterId:       invite.InviterID,
+		CreatedAt:       timestampOrNil(invite.CreatedAt),
+		ExpiresAt:       timestampOrNil(invite.ExpiresAt),
 	}
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"time"
+)
+
+// JoinCode is a shareable code any user can redeem to join a family without being invited personally.
+// MaxUses is zero for codes which can be redeemed any number of times
+// and ExpiresAt is zero for codes which never expire.
+type JoinCode struct {
+	Code      string    `bson:"code"`
+	FamilyID  int64     `bson:"family_id"`
+	CreatedBy int64     `bson:"created_by"`
+	MaxUses   int       `bson:"max_uses"`
+	Uses      int       `bson:"uses"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+	Disabled  bool      `bson:"disabled"`
+}
+
+// IsActive reports whether the code can be redeemed at the moment now:
+// it is not disabled, not expired and not used up.
+func (c *JoinCode) IsActive(now time.Time) bool {
+	switch {
+	case c.Disabled:
+		return false
+	case !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt):
+		return false
+	case c.MaxUses > 0 && c.Uses >= c.MaxUses:
+		return false
+	default:
+		return true
+	}
+}
+
+func ConvertToJoinCodeModel(code *JoinCode) *famv1.JoinCodeModel {
+	return &famv1.JoinCodeModel{
+		Code:      code.Code,
+		FamilyId:  code.FamilyID,
+		CreatedBy: code.CreatedBy,
+		MaxUses:   int32(code.MaxUses),
+		Uses:      int32(code.Uses),
+		CreatedAt: timestampOrNil(code.CreatedAt),
+		ExpiresAt: timestampOrNil(code.ExpiresAt),
+	}
+}
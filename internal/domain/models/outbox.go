@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type OutboxAction string
+
+const (
+	AddFamilyAction    OutboxAction = "add_family"
+	RemoveFamilyAction OutboxAction = "remove_family"
+)
+
+// OutboxEvent is a pending change of a user's family list in the SSO service.
+// It is stored together with the family change which caused it and removed
+// once it has been delivered. While an instance of the service delivers the event,
+// the event is leased to it: Owner identifies the instance and LockedUntil is the time
+// the lease expires. An event which can't be delivered is kept dead-lettered
+// with the error of its last attempt.
+type OutboxEvent struct {
+	ID             int64        `bson:"event_id"`
+	Action         OutboxAction `bson:"action"`
+	UserID         int64        `bson:"user_id"`
+	FamilyID       int64        `bson:"family_id"`
+	Attempts       int          `bson:"attempts"`
+	NextAttemptAt  time.Time    `bson:"next_attempt_at"`
+	CreatedAt      time.Time    `bson:"created_at"`
+	Owner          string       `bson:"owner,omitempty"`
+	LockedUntil    *time.Time   `bson:"locked_until,omitempty"`
+	DeadLetteredAt *time.Time   `bson:"dead_lettered_at,omitempty"`
+	LastError      string       `bson:"last_error,omitempty"`
+}
+
+// IsLocked reports whether the event is leased to an instance at the moment now.
+func (e *OutboxEvent) IsLocked(now time.Time) bool {
+	return e.LockedUntil != nil && e.LockedUntil.After(now)
+}
+
+// NewOutboxEvent creates an event which is ready to be delivered immediately.
+func NewOutboxEvent(action OutboxAction, userID, familyID int64) OutboxEvent {
+	now := time.Now().UTC()
+
+	return OutboxEvent{
+		Action:        action,
+		UserID:        userID,
+		FamilyID:      familyID,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+
+// FamilyListDrift is a mismatch between family membership and a user's family list in the SSO service.
+type FamilyListDrift struct {
+	UserID   int64
+	FamilyID int64
+}
+
+// ReconcileReport describes the drift found by a reconciliation run.
+// Missing contains families the user is a member of but which are absent from the SSO list,
+// Stale contains families listed in SSO although the user is not a member or the family does not exist.
+type ReconcileReport struct {
+	DryRun          bool
+	FamiliesChecked int
+	UsersChecked    int
+	Missing         []FamilyListDrift
+	Stale           []FamilyListDrift
+	FailedUsersID   []int64
+}
+
+func ConvertToReconcileResponse(report *ReconcileReport) *famv1.ReconcileResponse {
+	return &famv1.ReconcileResponse{
+		DryRun:          report.DryRun,
+		FamiliesChecked: int64(report.FamiliesChecked),
+		UsersChecked:    int64(report.UsersChecked),
+		Missing:         convertToDrifts(report.Missing),
+		Stale:           convertToDrifts(report.Stale),
+		FailedUserIds:   report.FailedUsersID,
+	}
+}
+
+func convertToDrifts(drifts []FamilyListDrift) []*famv1.FamilyListDrift {
+	res := make([]*famv1.FamilyListDrift, 0, len(drifts))
+
+	for _, drift := range drifts {
+		res = append(res, &famv1.FamilyListDrift{
+			UserId:   drift.UserID,
+			FamilyId: drift.FamilyID,
+		})
+	}
+
+	return res
+}
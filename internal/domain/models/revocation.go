@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RevokedToken is a single token revoked by its jti. It is kept until the token expires.
+type RevokedToken struct {
+	JTI       string    `bson:"jti"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// RevokedUser revokes all tokens of the user issued before RevokedBefore.
+type RevokedUser struct {
+	UserID        int64     `bson:"user_id"`
+	RevokedBefore time.Time `bson:"revoked_before"`
+}
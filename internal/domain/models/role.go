@@ -0,0 +1,69 @@
+package models
+
+import "slices"
+
+// FamilyRole is a role of a member within a single family.
+type FamilyRole string
+
+const (
+	OwnerRole    FamilyRole = "owner"
+	CoLeaderRole FamilyRole = "co_leader"
+	MemberRole   FamilyRole = "member"
+	ViewerRole   FamilyRole = "viewer"
+)
+
+// Permission is an action within a family which requires a specific role.
+type Permission int
+
+const (
+	ViewFamilyPermission Permission = iota
+	InvitePermission
+	RemoveMemberPermission
+	ManageRolesPermission
+	DeleteFamilyPermission
+	ManageFamilyPermission
+	RevokeInvitePermission
+)
+
+// permissions is the permission matrix of family roles.
+// Viewers currently have the same rights as members but rank below them,
+// so co-leaders can manage both while members can be granted more rights later.
+var permissions = map[FamilyRole][]Permission{
+	OwnerRole: {
+		ViewFamilyPermission, InvitePermission, RemoveMemberPermission,
+		ManageRolesPermission, DeleteFamilyPermission, ManageFamilyPermission,
+		RevokeInvitePermission,
+	},
+	CoLeaderRole: {ViewFamilyPermission, InvitePermission, RemoveMemberPermission},
+	MemberRole:   {ViewFamilyPermission},
+	ViewerRole:   {ViewFamilyPermission},
+}
+
+var ranks = map[FamilyRole]int{
+	OwnerRole:    4,
+	CoLeaderRole: 3,
+	MemberRole:   2,
+	ViewerRole:   1,
+}
+
+// Can reports whether the role grants the permission. An empty role, i.e. a non-member, grants nothing.
+func (r FamilyRole) Can(p Permission) bool {
+	return slices.Contains(permissions[r], p)
+}
+
+// Outranks reports whether the role is strictly higher than the other one.
+func (r FamilyRole) Outranks(other FamilyRole) bool {
+	return ranks[r] > ranks[other]
+}
+
+// IsValid reports whether the role is one of the known family roles.
+func (r FamilyRole) IsValid() bool {
+	_, ok := ranks[r]
+	return ok
+}
+
+// IsAssignable reports whether the role can be assigned with SetMemberRole.
+// The owner role is bound to the leadership of the family and can't be assigned directly.
+func (r FamilyRole) IsAssignable() bool {
+	return r.IsValid() && r != OwnerRole
+}
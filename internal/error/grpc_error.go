@@ -3,15 +3,32 @@ package grpcerror
 import "errors"
 
 var (
-	ErrInviteExist     = errors.New("user already invited")
-	ErrInviteNotFound  = errors.New("invite not found")
-	ErrUserInFamily    = errors.New("user already in family")
-	ErrUserNotInFamily = errors.New("user not in family")
-	ErrInternalError   = errors.New("internal error")
-	ErrUserNotFound    = errors.New("user not found")
-	ErrFamilyNotFound  = errors.New("family not found")
-	ErrNoToken         = errors.New("authorization token was not provided")
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrTokenClaims     = errors.New("failed to get token claims")
-	ErrForbidden       = errors.New("forbidden")
+	ErrInviteExist      = errors.New("user already invited")
+	ErrInviteNotFound   = errors.New("invite not found")
+	ErrInviteExpired    = errors.New("invite is expired")
+	ErrInvalidContact   = errors.New("invalid email or phone number")
+	ErrJoinCodeNotFound = errors.New("join code not found or no longer active")
+	ErrJoinCodeExist    = errors.New("join code already exists")
+	ErrInvalidJoinCode  = errors.New("invalid join code options")
+	ErrUserInFamily     = errors.New("user already in family")
+	ErrUserNotInFamily  = errors.New("user not in family")
+	ErrInvalidRole      = errors.New("invalid family role")
+	ErrNotLeader        = errors.New("user is not the leader of the family")
+	ErrTransferRequired = errors.New("leadership must be transferred before the leader leaves")
+	ErrInvalidPolicy    = errors.New("invalid succession policy")
+	ErrInvalidProfile   = errors.New("invalid family profile")
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+	ErrInvalidFilter    = errors.New("invalid family filter")
+	ErrInternalError    = errors.New("internal error")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrFamilyNotFound   = errors.New("family not found")
+	ErrNoToken          = errors.New("authorization token was not provided")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	ErrTokenIssuer      = errors.New("token issuer is not accepted")
+	ErrTokenAudience    = errors.New("token audience is not accepted")
+	ErrTokenRevoked     = errors.New("token is revoked")
+	ErrUnauthenticated  = errors.New("request is not authenticated")
+	ErrForbidden        = errors.New("forbidden")
 )
@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// ListFamilies retrieves a page of all families filtered by the leader, a member, the number of members
+// and the creation time (Unix times, created_after inclusive and created_before exclusive).
+// Families are sorted by sort_by (family_id, created_at or member_count; family_id by default),
+// in descending order if requested. The next_cursor field of the response is passed in the next request
+// with the same filter to get the following page; it is empty on the last page.
+func (s *serverAPI) ListFamilies(
+	ctx context.Context,
+	req *famv1.ListFamiliesRequest,
+) (*famv1.ListFamiliesResponse, error) {
+	const op = "admin.grpc.ListFamilies"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	filter := models.FamilyFilter{
+		LeaderID:   req.GetLeaderId(),
+		MemberID:   req.GetMemberId(),
+		MinMembers: int(req.GetMinMembers()),
+		MaxMembers: int(req.GetMaxMembers()),
+		SortBy:     models.FamilySort(req.GetSortBy()),
+		Descending: req.GetDescending(),
+	}
+
+	if req.GetCreatedAfter() > 0 {
+		filter.CreatedAfter = time.Unix(req.GetCreatedAfter(), 0)
+	}
+	if req.GetCreatedBefore() > 0 {
+		filter.CreatedBefore = time.Unix(req.GetCreatedBefore(), 0)
+	}
+
+	log.Info("listing families", slog.Any("filter", filter))
+
+	families, next, err := s.familySearch.SearchFamilies(ctx, filter, req.GetCursor(), int(req.GetLimit()))
+	if errors.Is(err, grpcerror.ErrInvalidFilter) {
+		log.Warn("invalid family filter", sl.Err(err))
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidFilter.Error())
+	}
+	if errors.Is(err, grpcerror.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidCursor.Error())
+	}
+	if err != nil {
+		log.Error("failed to list families", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("successfully listed families", slog.Int("count", len(families)))
+
+	resp := make([]*famv1.FamilyModel, 0, len(families))
+
+	for _, family := range families {
+		resp = append(resp, models.ConvertToFamilyModel(&family))
+	}
+
+	return &famv1.ListFamiliesResponse{
+		Families:   resp,
+		NextCursor: next,
+	}, nil
+}
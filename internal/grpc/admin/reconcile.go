@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// Reconcile compares family membership with users' family lists in the SSO service and repairs the drift.
+// In dry-run mode it only reports the drift without changing anything.
+func (s *serverAPI) Reconcile(
+	ctx context.Context,
+	req *famv1.ReconcileRequest,
+) (*famv1.ReconcileResponse, error) {
+	const op = "admin.grpc.Reconcile"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("starting reconciliation",
+		slog.Bool("dry_run", req.GetDryRun()),
+		slog.Any("user_ids", req.GetUserIds()))
+
+	report, err := s.reconciler.Reconcile(ctx, req.GetDryRun(), req.GetUserIds())
+	if err != nil {
+		log.Error("failed to reconcile", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("reconciliation finished",
+		slog.Int("missing", len(report.Missing)),
+		slog.Int("stale", len(report.Stale)),
+		slog.Int("failed", len(report.FailedUsersID)))
+
+	return models.ConvertToReconcileResponse(report), nil
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// RevokeToken revokes a single token by its jti. ExpiresAt is the Unix time the token expires at;
+// when it is omitted the revocation is kept for the configured token TTL.
+func (s *serverAPI) RevokeToken(
+	ctx context.Context,
+	req *famv1.RevokeTokenRequest,
+) (*famv1.RevokeTokenResponse, error) {
+	const op = "admin.grpc.RevokeToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if req.GetJti() == "" {
+		return nil, status.Error(codes.InvalidArgument, "jti is required")
+	}
+
+	var expiresAt time.Time
+	if req.GetExpiresAt() > 0 {
+		expiresAt = time.Unix(req.GetExpiresAt(), 0)
+	}
+
+	err := s.revocation.RevokeToken(ctx, req.GetJti(), expiresAt)
+	if err != nil {
+		log.Error("failed to revoke token", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	return &famv1.RevokeTokenResponse{
+		Succeed: true,
+	}, nil
+}
+
+// RevokeUserTokens revokes all tokens of the user issued before IssuedBefore (Unix time),
+// or all tokens issued up to now when it is omitted.
+func (s *serverAPI) RevokeUserTokens(
+	ctx context.Context,
+	req *famv1.RevokeUserTokensRequest,
+) (*famv1.RevokeUserTokensResponse, error) {
+	const op = "admin.grpc.RevokeUserTokens"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if req.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	var issuedBefore time.Time
+	if req.GetIssuedBefore() > 0 {
+		issuedBefore = time.Unix(req.GetIssuedBefore(), 0)
+	}
+
+	err := s.revocation.RevokeUserTokens(ctx, req.GetUserId(), issuedBefore)
+	if err != nil {
+		log.Error("failed to revoke user's tokens", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	return &famv1.RevokeUserTokensResponse{
+		Succeed: true,
+	}, nil
+}
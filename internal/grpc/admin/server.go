@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc"
+	"log/slog"
+)
+
+type serverAPI struct {
+	famv1.UnimplementedAdminServer
+	log          *slog.Logger
+	reconciler   services.Reconciler
+	userCache    services.UserCache
+	revocation   services.Revocation
+	familySearch services.FamilySearch
+}
+
+// Register associates the gRPC implementation of the Admin service with the provided gRPC server.
+func Register(
+	gRPC *grpc.Server,
+	log *slog.Logger,
+	reconciler services.Reconciler,
+	userCache services.UserCache,
+	revocation services.Revocation,
+	familySearch services.FamilySearch) {
+	famv1.RegisterAdminServer(gRPC, &serverAPI{
+		log:          log,
+		reconciler:   reconciler,
+		userCache:    userCache,
+		revocation:   revocation,
+		familySearch: familySearch,
+	})
+}
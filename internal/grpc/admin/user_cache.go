@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"log/slog"
+)
+
+// InvalidateUserCache drops the cached SSO profile of the user with the given ID,
+// so that changes of the profile become visible immediately.
+func (s *serverAPI) InvalidateUserCache(
+	_ context.Context,
+	req *famv1.InvalidateUserCacheRequest,
+) (*famv1.InvalidateUserCacheResponse, error) {
+	const op = "admin.grpc.InvalidateUserCache"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("invalidating user's cached profile",
+		slog.Int64("user_id", req.GetUserId()))
+
+	s.userCache.InvalidateUser(req.GetUserId())
+
+	return &famv1.InvalidateUserCacheResponse{
+		Succeed: true,
+	}, nil
+}
+
+// GetUserCacheStats returns the hit ratio and counters of the SSO profile cache.
+func (s *serverAPI) GetUserCacheStats(
+	_ context.Context,
+	_ *famv1.GetUserCacheStatsRequest,
+) (*famv1.GetUserCacheStatsResponse, error) {
+	return models.ConvertToCacheStatsResponse(s.userCache.Stats()), nil
+}
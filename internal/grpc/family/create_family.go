@@ -2,6 +2,8 @@ package family
 
 import (
 	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
@@ -10,11 +12,13 @@ import (
 	"log/slog"
 )
 
-// CreateFamily creates a new family and also adds it to the user's family list.
-// It logs information about the operation, such as creating the family and adding it to the user's list.
+// CreateFamily creates a new family with the given name, description and avatar URL
+// and the user as its leader. The family is added to the
+// user's family list in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as creating the family.
 func (s *serverAPI) CreateFamily(
 	ctx context.Context,
-	_ *famv1.CreateFamilyRequest,
+	req *famv1.CreateFamilyRequest,
 ) (*famv1.CreateFamilyResponse, error) {
 	const op = "family.grpc.CreateFamily"
 
@@ -24,22 +28,26 @@ func (s *serverAPI) CreateFamily(
 
 	log.Info("creating family")
 
-	familyID, err := s.family.CreateFamily(ctx)
+	family, err := s.family.CreateFamily(ctx, models.FamilyProfile{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		AvatarURL:   req.GetAvatarUrl(),
+	})
+	if errors.Is(err, grpcerror.ErrInvalidProfile) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidProfile.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
 		log.Error("failed to create family", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
 	}
 
-	log.Info("family created", slog.Int64("family_id", familyID))
-
-	if err = s.sso.AddFamilyToList(ctx, familyID); err != nil {
-		log.Error("failed to add family to user's family list", sl.Err(err))
-		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
-	}
-
-	log.Info("family added to user's family list")
+	log.Info("family created", slog.Int64("family_id", family.ID))
 
 	return &famv1.CreateFamilyResponse{
-		FamilyId: familyID,
+		FamilyId: family.ID,
+		Family:   models.ConvertToFamilyModel(&family),
 	}, nil
 }
@@ -12,7 +12,9 @@ import (
 	"log/slog"
 )
 
-// GetFamilyInfo retrieves information about the members of a family identified by the given family ID.
+// GetFamilyInfo retrieves the profile of a family identified by the given family ID together with
+// its members' roles, join times and information. Members' information is requested from the SSO service in parallel; members whose information
+// could not be retrieved are listed in the failed_user_ids field of the response.
 // It logs information about the operation, such as retrieving family members' information and handling any errors.
 func (s *serverAPI) GetFamilyInfo(
 	ctx context.Context,
@@ -27,7 +29,7 @@ func (s *serverAPI) GetFamilyInfo(
 	log.Info("getting family info",
 		slog.Int64("family_id", req.GetFamilyId()))
 
-	IDs, err := s.family.GetFamilyMembersIDs(ctx, req.GetFamilyId())
+	family, err := s.family.GetFamily(ctx, req.GetFamilyId())
 	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
 		log.Warn(grpcerror.ErrFamilyNotFound.Error(),
 			slog.Int64("family_id", req.GetFamilyId()))
@@ -36,36 +38,39 @@ func (s *serverAPI) GetFamilyInfo(
 	if errors.Is(err, grpcerror.ErrForbidden) {
 		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
 	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
-		log.Error("failed to get members' id", sl.Err(err))
+		log.Error("failed to get family", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
 	}
 
-	log.Info("successfully got family members' ids")
+	log.Info("successfully got family")
 
-	info := make([]*famv1.UserInfo, 0, len(IDs))
+	members := make([]*famv1.MemberModel, 0, len(family.Members))
 
-	damaged := make([]int64, 0)
+	for _, member := range family.Members {
+		members = append(members, models.ConvertToMemberModel(&member))
+	}
 
-	for _, userID := range IDs {
-		user, err := s.sso.GetUserInfo(userID)
-		if err != nil {
-			log.Error("user's info is missing",
-				sl.Err(err), slog.Int64("user_id", userID))
-			damaged = append(damaged, userID)
-			continue
-		}
+	users, notFound, failed := s.sso.GetUsersInfo(ctx, family.MembersID)
+	failed = append(notFound, failed...)
 
-		info = append(info, models.ConvertToInfo(user))
-	}
+	info := make([]*famv1.UserInfo, 0, len(users))
 
-	resp := &famv1.GetFamilyInfoResponse{
-		Info: info,
+	for _, user := range users {
+		info = append(info, models.ConvertToInfo(user))
 	}
 
-	if len(damaged) > 0 {
-		return resp, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	if len(failed) > 0 {
+		log.Warn("failed to get info of some members", slog.Any("user_ids", failed))
 	}
 
-	return resp, nil
+	return &famv1.GetFamilyInfoResponse{
+		Info:          info,
+		FailedUserIds: failed,
+		Family:        models.ConvertToFamilyModel(&family),
+		Members:       members,
+	}, nil
 }
@@ -12,7 +12,8 @@ import (
 )
 
 // LeaveFamily allows a user to leave a family identified by the given family ID.
-// It logs information about the operation, such as leaving the family and removing the family from the user's family list.
+// The family is removed from the user's family list in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as leaving the family.
 func (s *serverAPI) LeaveFamily(
 	ctx context.Context,
 	req *famv1.LeaveFamilyRequest,
@@ -26,7 +27,7 @@ func (s *serverAPI) LeaveFamily(
 	log.Info("leaving family",
 		slog.Int64("family_id", req.GetFamilyId()))
 
-	userID, err := s.family.LeaveFamily(ctx, req.GetFamilyId())
+	_, err := s.family.LeaveFamily(ctx, req.GetFamilyId())
 	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
 		log.Warn("failed to find family")
 		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
@@ -35,6 +36,12 @@ func (s *serverAPI) LeaveFamily(
 		log.Warn("failed to find user in family")
 		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrUserNotFound.Error())
 	}
+	if errors.Is(err, grpcerror.ErrTransferRequired) {
+		return nil, status.Error(codes.FailedPrecondition, grpcerror.ErrTransferRequired.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
 		log.Error("failed to leave family", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
@@ -42,14 +49,6 @@ func (s *serverAPI) LeaveFamily(
 
 	log.Info("successfully leaved the family")
 
-	err = s.sso.RemoveFamilyFromList(userID, req.GetFamilyId())
-	if err != nil {
-		log.Error("failed to delete family from user's family list", sl.Err(err))
-		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
-	}
-
-	log.Info("successfully deleted family from user's family list")
-
 	return &famv1.LeaveFamilyResponse{
 		Succeed: true,
 	}, nil
@@ -0,0 +1,55 @@
+package family
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// ListMyFamilies retrieves a page of the families the user making the request is a member of.
+// Each family is returned with the user's role in it and the number of its members.
+// The next_cursor field of the response is passed in the next request to get the following page;
+// it is empty on the last page.
+func (s *serverAPI) ListMyFamilies(
+	ctx context.Context,
+	req *famv1.ListMyFamiliesRequest,
+) (*famv1.ListMyFamiliesResponse, error) {
+	const op = "family.grpc.ListMyFamilies"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("listing user's families")
+
+	summaries, next, err := s.family.ListMyFamilies(ctx, req.GetCursor(), int(req.GetLimit()))
+	if errors.Is(err, grpcerror.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidCursor.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to list families", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("successfully listed families", slog.Int("count", len(summaries)))
+
+	families := make([]*famv1.FamilySummary, 0, len(summaries))
+
+	for _, summary := range summaries {
+		families = append(families, models.ConvertToFamilySummary(&summary))
+	}
+
+	return &famv1.ListMyFamiliesResponse{
+		Families:   families,
+		NextCursor: next,
+	}, nil
+}
@@ -12,7 +12,8 @@ import (
 )
 
 // DeleteFamily deletes the family with the given family ID from the system.
-// It logs information about the operation, such as attempting to delete the family and removing the family from users' family lists.
+// The family is removed from members' family lists in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as attempting to delete the family.
 func (s *serverAPI) DeleteFamily(
 	ctx context.Context,
 	req *famv1.DeleteFamilyRequest,
@@ -33,22 +34,17 @@ func (s *serverAPI) DeleteFamily(
 	if errors.Is(err, grpcerror.ErrForbidden) {
 		return nil, status.Error(codes.PermissionDenied, err.Error())
 	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
+		log.Error("failed to delete family", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
 	}
 
-	log.Info("family deleted, removing its id from users' lists")
-
-	for _, id := range members {
-		err = s.sso.RemoveFamilyFromList(id, req.GetFamilyId())
-		if err != nil {
-			log.Warn("failed to delete family from user's family list",
-				slog.Int64("user_id", id), sl.Err(err))
-		}
-	}
-
 	log.Info("family deleted",
-		slog.Int64("family_id", req.GetFamilyId()))
+		slog.Int64("family_id", req.GetFamilyId()),
+		slog.Int("members", len(members)))
 
 	return &famv1.DeleteFamilyResponse{
 		Succeed: true,
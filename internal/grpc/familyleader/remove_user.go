@@ -12,7 +12,8 @@ import (
 )
 
 // RemoveUser removes a user with the given user ID from the specified family.
-// It logs information about the operation, such as attempting to remove the user from the family and removing the family from the user's family list.
+// The family is removed from the user's family list in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as attempting to remove the user from the family.
 func (s *serverAPI) RemoveUser(
 	ctx context.Context,
 	req *famv1.RemoveUserRequest,
@@ -41,20 +42,19 @@ func (s *serverAPI) RemoveUser(
 	if errors.Is(err, grpcerror.ErrForbidden) {
 		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
 	}
+	if errors.Is(err, grpcerror.ErrTransferRequired) {
+		return nil, status.Error(codes.FailedPrecondition, grpcerror.ErrTransferRequired.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
+		log.Error("failed to remove user from family", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
 	}
 
 	log.Info("user removed from family")
 
-	log.Info("trying to remove family from user's list")
-
-	err = s.sso.RemoveFamilyFromList(req.GetUserId(), req.GetFamilyId())
-	if err != nil {
-		log.Error("failed to remove family from user's list", sl.Err(err))
-		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
-	}
-
 	return &famv1.RemoveUserResponse{
 		UserId: req.GetUserId(),
 	}, nil
@@ -11,18 +11,15 @@ type serverAPI struct {
 	famv1.UnimplementedFamilyLeaderServer
 	log          *slog.Logger
 	familyLeader services.FamilyLeader
-	sso          services.SSO
 }
 
 // Register associates the gRPC implementation of the Auth service with the provided gRPC server.
 func Register(
 	gRPC *grpc.Server,
 	log *slog.Logger,
-	familyLeader services.FamilyLeader,
-	sso services.SSO) {
+	familyLeader services.FamilyLeader) {
 	famv1.RegisterFamilyLeaderServer(gRPC, &serverAPI{
 		log:          log,
 		familyLeader: familyLeader,
-		sso:          sso,
 	})
 }
@@ -0,0 +1,59 @@
+package familyleader
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// SetMemberRole promotes or demotes a member of the family to the given role
+// (co_leader, member or viewer). Only the owner of the family or an admin can change roles.
+func (s *serverAPI) SetMemberRole(
+	ctx context.Context,
+	req *famv1.SetMemberRoleRequest,
+) (*famv1.SetMemberRoleResponse, error) {
+	const op = "familyleader.grpc.SetMemberRole"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to set member role",
+		slog.Int64("family_id", req.GetFamilyId()),
+		slog.Int64("user_id", req.GetUserId()),
+		slog.String("role", req.GetRole()))
+
+	err := s.familyLeader.SetMemberRole(ctx,
+		req.GetFamilyId(), req.GetUserId(), models.FamilyRole(req.GetRole()))
+	if errors.Is(err, grpcerror.ErrInvalidRole) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidRole.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrUserNotInFamily.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to set member role", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("member role successfully changed")
+
+	return &famv1.SetMemberRoleResponse{
+		Succeed: true,
+	}, nil
+}
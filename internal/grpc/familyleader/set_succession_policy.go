@@ -0,0 +1,56 @@
+package familyleader
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// SetSuccessionPolicy configures who becomes the leader when the leader leaves the family:
+// the longest-tenured member (longest_tenured), the member with the oldest account (oldest_member),
+// or nobody until the leadership is transferred explicitly (require_transfer).
+func (s *serverAPI) SetSuccessionPolicy(
+	ctx context.Context,
+	req *famv1.SetSuccessionPolicyRequest,
+) (*famv1.SetSuccessionPolicyResponse, error) {
+	const op = "familyleader.grpc.SetSuccessionPolicy"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to set succession policy",
+		slog.Int64("family_id", req.GetFamilyId()),
+		slog.String("policy", req.GetPolicy()))
+
+	err := s.familyLeader.SetSuccessionPolicy(ctx,
+		req.GetFamilyId(), models.SuccessionPolicy(req.GetPolicy()))
+	if errors.Is(err, grpcerror.ErrInvalidPolicy) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidPolicy.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to set succession policy", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("succession policy successfully changed")
+
+	return &famv1.SetSuccessionPolicyResponse{
+		Succeed: true,
+	}, nil
+}
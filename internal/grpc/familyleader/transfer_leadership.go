@@ -0,0 +1,56 @@
+package familyleader
+
+import (
+	"context"
+	"errors"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// TransferLeadership hands the leadership of the family over to the chosen member.
+// Only the current leader of the family or an admin can transfer the leadership.
+func (s *serverAPI) TransferLeadership(
+	ctx context.Context,
+	req *famv1.TransferLeadershipRequest,
+) (*famv1.TransferLeadershipResponse, error) {
+	const op = "familyleader.grpc.TransferLeadership"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to transfer leadership",
+		slog.Int64("family_id", req.GetFamilyId()),
+		slog.Int64("user_id", req.GetUserId()))
+
+	err := s.familyLeader.TransferLeadership(ctx, req.GetFamilyId(), req.GetUserId())
+	if errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrUserNotInFamily.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrNotLeader) {
+		return nil, status.Error(codes.Aborted, grpcerror.ErrNotLeader.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to transfer leadership", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("leadership successfully transferred")
+
+	return &famv1.TransferLeadershipResponse{
+		Succeed: true,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package familyleader
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// UpdateFamily changes the name, description or avatar URL of the family.
+// Fields which are not set in the request are left unchanged.
+// Only the leader of the family or an admin can update it.
+func (s *serverAPI) UpdateFamily(
+	ctx context.Context,
+	req *famv1.UpdateFamilyRequest,
+) (*famv1.UpdateFamilyResponse, error) {
+	const op = "familyleader.grpc.UpdateFamily"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to update family",
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	family, err := s.familyLeader.UpdateFamily(ctx, req.GetFamilyId(), models.FamilyUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		AvatarURL:   req.AvatarUrl,
+	})
+	if errors.Is(err, grpcerror.ErrInvalidProfile) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidProfile.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to update family", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("family successfully updated")
+
+	return &famv1.UpdateFamilyResponse{
+		Family: models.ConvertToFamilyModel(&family),
+	}, nil
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"errors"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log/slog"
 )
 
-// AcceptInvite accepts an invitation with the given invite ID and adds the associated family to the user's family list.
-// It logs information about the operation, such as attempting to accept the invite and adding the family to the user's family list.
+// AcceptInvite accepts an invitation with the given invite ID and adds the user to the associated family.
+// The family is added to the user's family list in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as attempting to accept the invite.
 func (s *serverAPI) AcceptInvite(
 	ctx context.Context,
 	req *famv1.AcceptInviteRequest,
@@ -29,18 +31,24 @@ func (s *serverAPI) AcceptInvite(
 	if errors.Is(err, grpcerror.ErrInviteNotFound) {
 		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInviteNotFound.Error())
 	}
-	if err != nil {
-		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	if errors.Is(err, grpcerror.ErrInviteExpired) {
+		return nil, status.Error(codes.FailedPrecondition, grpcerror.ErrInviteExpired.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUserInFamily) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrUserInFamily.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
 	}
-
-	log.Info("invite accepted, trying to add family to user's family list")
-
-	err = s.sso.AddFamilyToList(ctx, familyID)
 	if err != nil {
+		log.Error("failed to accept invite", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
 	}
 
-	log.Info("family added to user's family list")
+	log.Info("invite accepted", slog.Int64("family_id", familyID))
 
 	return &famv1.AcceptInviteResponse{
 		FamilyId: familyID,
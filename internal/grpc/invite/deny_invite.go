@@ -2,6 +2,7 @@ package invite
 
 import (
 	"context"
+	"errors"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
@@ -26,6 +27,9 @@ func (s *serverAPI) DenyInvite(
 		slog.Int64("invite_id", req.GetInviteId()))
 
 	err := s.invite.DenyInvite(ctx, req.InviteId)
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
 		log.Error("failed to deny invite", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
@@ -2,6 +2,7 @@ package invite
 
 import (
 	"context"
+	"errors"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
@@ -25,6 +26,9 @@ func (s *serverAPI) GetInvites(
 	log.Info("retrieving invites of user")
 
 	invites, err := s.invite.GetInvites(ctx)
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
 		log.Error("failed to retrieve invites", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
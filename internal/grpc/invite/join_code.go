@@ -0,0 +1,176 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// CreateJoinCode generates a join code for the specified family. The code is limited to max_uses redemptions
+// and expires at expires_at (a Unix time) when they are set.
+// It logs information about the operation, such as attempting to create the code and whether the operation was successful.
+func (s *serverAPI) CreateJoinCode(
+	ctx context.Context,
+	req *famv1.CreateJoinCodeRequest,
+) (*famv1.CreateJoinCodeResponse, error) {
+	const op = "invite.grpc.CreateJoinCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to create join code",
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	var expiresAt time.Time
+	if req.GetExpiresAt() > 0 {
+		expiresAt = time.Unix(req.GetExpiresAt(), 0)
+	}
+
+	code, err := s.joinCode.CreateJoinCode(ctx, req.GetFamilyId(), int(req.GetMaxUses()), expiresAt)
+	if errors.Is(err, grpcerror.ErrInvalidJoinCode) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidJoinCode.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to create join code", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("join code successfully created")
+
+	return &famv1.CreateJoinCodeResponse{
+		JoinCode: models.ConvertToJoinCodeModel(&code),
+	}, nil
+}
+
+// ListJoinCodes retrieves the active join codes of the specified family.
+// It logs information about the operation, such as attempting to retrieve the codes and whether the operation was successful.
+func (s *serverAPI) ListJoinCodes(
+	ctx context.Context,
+	req *famv1.ListJoinCodesRequest,
+) (*famv1.ListJoinCodesResponse, error) {
+	const op = "invite.grpc.ListJoinCodes"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("retrieving join codes of family",
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	joinCodes, err := s.joinCode.ListJoinCodes(ctx, req.GetFamilyId())
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to retrieve join codes", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	res := make([]*famv1.JoinCodeModel, 0, len(joinCodes))
+	for _, code := range joinCodes {
+		res = append(res, models.ConvertToJoinCodeModel(&code))
+	}
+
+	log.Info("join codes successfully retrieved")
+
+	return &famv1.ListJoinCodesResponse{
+		JoinCodes: res,
+	}, nil
+}
+
+// DisableJoinCode disables the specified join code of the family, so it can no longer be redeemed.
+// It logs information about the operation, such as attempting to disable the code and whether the operation was successful.
+func (s *serverAPI) DisableJoinCode(
+	ctx context.Context,
+	req *famv1.DisableJoinCodeRequest,
+) (*famv1.DisableJoinCodeResponse, error) {
+	const op = "invite.grpc.DisableJoinCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to disable join code",
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	err := s.joinCode.DisableJoinCode(ctx, req.GetFamilyId(), req.GetCode())
+	if errors.Is(err, grpcerror.ErrJoinCodeNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrJoinCodeNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to disable join code", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("join code is successfully disabled")
+
+	return &famv1.DisableJoinCodeResponse{
+		Succeed: true,
+	}, nil
+}
+
+// JoinByCode adds the user to the family of the join code.
+// The family is added to the user's family list in the SSO service asynchronously by the outbox dispatcher.
+// It logs information about the operation, such as attempting to join the family.
+func (s *serverAPI) JoinByCode(
+	ctx context.Context,
+	req *famv1.JoinByCodeRequest,
+) (*famv1.JoinByCodeResponse, error) {
+	const op = "invite.grpc.JoinByCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to join family by code")
+
+	familyID, err := s.joinCode.JoinByCode(ctx, req.GetCode())
+	if errors.Is(err, grpcerror.ErrJoinCodeNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrJoinCodeNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUserInFamily) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrUserInFamily.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to join family by code", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("joined family by code", slog.Int64("family_id", familyID))
+
+	return &famv1.JoinByCodeResponse{
+		FamilyId: familyID,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// ListFamilyInvites retrieves the invites sent to the specified family, together with the pending
+// invites addressed to emails and phone numbers, which are returned in their own list.
+// It logs information about the operation, such as attempting to retrieve the invites and whether the operation was successful.
+func (s *serverAPI) ListFamilyInvites(
+	ctx context.Context,
+	req *famv1.ListFamilyInvitesRequest,
+) (*famv1.ListFamilyInvitesResponse, error) {
+	const op = "invite.grpc.ListFamilyInvites"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("retrieving invites of family",
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	invites, pending, err := s.invite.ListFamilyInvites(ctx, req.GetFamilyId())
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to retrieve invites of family", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("invites of family successfully retrieved")
+
+	return &famv1.ListFamilyInvitesResponse{
+		Invites:        invites,
+		PendingInvites: pending,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// RevokeInvite withdraws the invite with the specified invite ID sent to the specified family,
+// or its pending invite with the specified pending invite ID. Exactly one of the IDs must be set.
+// It logs information about the operation, such as attempting to revoke the invite and whether the operation was successful.
+func (s *serverAPI) RevokeInvite(
+	ctx context.Context,
+	req *famv1.RevokeInviteRequest,
+) (*famv1.RevokeInviteResponse, error) {
+	const op = "invite.grpc.RevokeInvite"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("trying to revoke invite",
+		slog.Int64("family_id", req.GetFamilyId()),
+		slog.Int64("invite_id", req.GetInviteId()),
+		slog.Int64("pending_invite_id", req.GetPendingInviteId()))
+
+	if (req.GetInviteId() == 0) == (req.GetPendingInviteId() == 0) {
+		log.Warn("either invite id or pending invite id must be set")
+		return nil, status.Error(codes.InvalidArgument, "either invite_id or pending_invite_id must be set")
+	}
+
+	var err error
+
+	if req.GetPendingInviteId() != 0 {
+		err = s.invite.RevokePendingInvite(ctx, req.GetFamilyId(), req.GetPendingInviteId())
+	} else {
+		err = s.invite.RevokeInvite(ctx, req.GetFamilyId(), req.GetInviteId())
+	}
+	if errors.Is(err, grpcerror.ErrInviteNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInviteNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to revoke invite", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("invite is successfully revoked")
+
+	return &famv1.RevokeInviteResponse{
+		Succeed: true,
+	}, nil
+}
@@ -3,6 +3,7 @@ package invite
 import (
 	"context"
 	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
@@ -11,7 +12,10 @@ import (
 	"log/slog"
 )
 
-// SendInvite sends an invitation to a user to join a family.
+// SendInvite sends an invitation to a user to join a family. Instead of the ID of a registered user,
+// the invitation can be addressed to an email or a phone number, in which case it stays pending
+// until a user with that contact gets their invites, and the ID of the pending invitation is returned
+// in pending_invite_id instead of invite_id, as pending invitations are numbered apart from regular ones.
 // It logs information about the operation, such as sending the invite and whether the operation was successful.
 func (s *serverAPI) SendInvite(
 	ctx context.Context,
@@ -23,11 +27,15 @@ func (s *serverAPI) SendInvite(
 		slog.String("op", op),
 	)
 
+	if req.GetUserId() == 0 && (req.GetEmail() != "" || req.GetPhoneNumber() != "") {
+		return s.sendContactInvite(ctx, log, req)
+	}
+
 	log.Info("sending invite to user",
 		slog.Int64("user_id", req.GetUserId()),
 		slog.Int64("family_id", req.GetFamilyId()))
 
-	_, err := s.sso.GetUserInfo(req.GetUserId())
+	_, err := s.sso.GetUserInfo(ctx, req.GetUserId())
 	if err != nil {
 		log.Warn(grpcerror.ErrUserNotFound.Error())
 		return nil, status.Error(codes.Internal, grpcerror.ErrUserNotFound.Error())
@@ -45,6 +53,9 @@ func (s *serverAPI) SendInvite(
 	if errors.Is(err, grpcerror.ErrForbidden) {
 		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrForbidden.Error())
 	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
 	if err != nil {
 		log.Error("failed to send invite to user", sl.Err(err))
 		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
@@ -56,3 +67,47 @@ func (s *serverAPI) SendInvite(
 		InviteId: inviteID,
 	}, nil
 }
+
+// sendContactInvite sends a pending invitation addressed to the email or the phone number of the request.
+// The contact itself is not logged.
+func (s *serverAPI) sendContactInvite(
+	ctx context.Context,
+	log *slog.Logger,
+	req *famv1.SendInviteRequest,
+) (*famv1.SendInviteResponse, error) {
+	log.Info("sending invite to contact",
+		slog.Bool("email", req.GetEmail() != ""),
+		slog.Bool("phone_number", req.GetPhoneNumber() != ""),
+		slog.Int64("family_id", req.GetFamilyId()))
+
+	inviteID, err := s.invite.SendContactInvite(ctx, req.GetFamilyId(), models.Contact{
+		Email:       req.GetEmail(),
+		PhoneNumber: req.GetPhoneNumber(),
+	})
+	if errors.Is(err, grpcerror.ErrInvalidContact) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInvalidContact.Error())
+	}
+	if errors.Is(err, grpcerror.ErrInviteExist) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrInviteExist.Error())
+	}
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrFamilyNotFound.Error())
+	}
+	if errors.Is(err, grpcerror.ErrForbidden) {
+		return nil, status.Error(codes.InvalidArgument, grpcerror.ErrForbidden.Error())
+	}
+	if errors.Is(err, grpcerror.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, grpcerror.ErrUnauthenticated.Error())
+	}
+	if err != nil {
+		log.Error("failed to send invite to contact", sl.Err(err))
+		return nil, status.Error(codes.Internal, grpcerror.ErrInternalError.Error())
+	}
+
+	log.Info("pending invite successfully sent")
+
+	return &famv1.SendInviteResponse{
+		Pending:         true,
+		PendingInviteId: inviteID,
+	}, nil
+}
@@ -9,9 +9,10 @@ import (
 
 type serverAPI struct {
 	famv1.UnimplementedInviteServer
-	log    *slog.Logger
-	invite services.Invite
-	sso    services.SSO
+	log      *slog.Logger
+	invite   services.Invite
+	joinCode services.JoinCode
+	sso      services.SSO
 }
 
 // Register associates the gRPC implementation of the Auth service with the provided gRPC server.
@@ -19,10 +20,12 @@ func Register(
 	gRPC *grpc.Server,
 	log *slog.Logger,
 	invite services.Invite,
+	joinCode services.JoinCode,
 	sso services.SSO) {
 	famv1.RegisterInviteServer(gRPC, &serverAPI{
-		log:    log,
-		invite: invite,
-		sso:    sso,
+		log:      log,
+		invite:   invite,
+		joinCode: joinCode,
+		sso:      sso,
 	})
 }
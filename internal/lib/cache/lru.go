@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a size-bounded cache which evicts the least recently used entry when full.
+// Every entry expires after the TTL it was stored with. LRU is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates an LRU cache holding at most size entries.
+func NewLRU[K comparable, V any](size int) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:  max(size, 1),
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value stored for key if it is present and has not expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e, _ := el.Value.(*entry[K, V])
+
+	if time.Now().After(e.expiresAt) {
+		c.remove(el)
+		return zero, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return e.value, true
+}
+
+// Set stores value for key for the duration of ttl, evicting the least recently used entry if the cache is full.
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		e, _ := el.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[K, V]{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	if c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Delete removes the entry stored for key.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones not evicted yet.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// remove deletes the element from the cache. The caller must hold the lock.
+func (c *LRU[K, V]) remove(el *list.Element) {
+	e, _ := c.ll.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+}
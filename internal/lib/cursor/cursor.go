@@ -0,0 +1,79 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"fmt"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"strconv"
+	"strings"
+)
+
+const prefix = "v1:"
+
+// Encode returns an opaque pagination cursor pointing after the item with the given ID.
+func Encode(lastID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(prefix + strconv.FormatInt(lastID, 10)))
+}
+
+// Decode returns the ID encoded in the cursor. An empty cursor points to the first page and decodes to 0.
+func Decode(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", grpcerror.ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), prefix), 10, 64)
+	if err != nil || !strings.HasPrefix(string(raw), prefix) || id < 0 {
+		return 0, grpcerror.ErrInvalidCursor
+	}
+
+	return id, nil
+}
+
+// Position is a place in a list sorted by Key with ties broken by ID.
+// Sort names the order the list is sorted in, so a cursor cannot be reused with another one.
+type Position struct {
+	Sort string
+	Key  int64
+	ID   int64
+}
+
+// EncodePosition returns an opaque pagination cursor pointing after the given position.
+func EncodePosition(pos Position) string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%s%s:%d:%d", prefix, pos.Sort, pos.Key, pos.ID)))
+}
+
+// DecodePosition returns the position encoded in the cursor. An empty cursor points to the first page
+// and decodes to nil. A cursor encoded for another sort order is invalid.
+func DecodePosition(cursor, sort string) (*Position, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", grpcerror.ErrInvalidCursor, err)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(raw), prefix), ":")
+	if !strings.HasPrefix(string(raw), prefix) || len(parts) != 3 || parts[0] != sort {
+		return nil, grpcerror.ErrInvalidCursor
+	}
+
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, grpcerror.ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, grpcerror.ErrInvalidCursor
+	}
+
+	return &Position{Sort: sort, Key: key, ID: id}, nil
+}
@@ -0,0 +1,105 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		want    int64
+		wantErr bool
+	}{
+		{name: "empty", cursor: "", want: 0},
+		{name: "encoded", cursor: Encode(42), want: 42},
+		{name: "not base64", cursor: "v1:42!", wantErr: true},
+		{name: "without prefix", cursor: raw("42"), wantErr: true},
+		{name: "other version", cursor: raw("v2:42"), wantErr: true},
+		{name: "not a number", cursor: raw("v1:abc"), wantErr: true},
+		{name: "negative", cursor: raw("v1:-1"), wantErr: true},
+		{name: "position cursor", cursor: EncodePosition(Position{Sort: "id", Key: 42, ID: 42}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.cursor)
+
+			if tt.wantErr {
+				if !errors.Is(err, grpcerror.ErrInvalidCursor) {
+					t.Fatalf("Decode() error = %v, want %v", err, grpcerror.ErrInvalidCursor)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Decode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePosition(t *testing.T) {
+	pos := Position{Sort: "member_count-desc", Key: 3, ID: 17}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		sort    string
+		want    *Position
+		wantErr bool
+	}{
+		{name: "empty", cursor: "", sort: "id"},
+		{name: "encoded", cursor: EncodePosition(pos), sort: pos.Sort, want: &pos},
+		{
+			name:   "negative key",
+			cursor: EncodePosition(Position{Sort: "created_at", Key: -5, ID: 1}),
+			sort:   "created_at",
+			want:   &Position{Sort: "created_at", Key: -5, ID: 1},
+		},
+		{name: "other sort field", cursor: EncodePosition(pos), sort: "created_at-desc", wantErr: true},
+		{name: "other direction", cursor: EncodePosition(pos), sort: "member_count", wantErr: true},
+		{name: "id cursor", cursor: Encode(17), sort: "id", wantErr: true},
+		{name: "not base64", cursor: "v1:id:1:1", sort: "id", wantErr: true},
+		{name: "without prefix", cursor: raw("id:1:1"), sort: "id", wantErr: true},
+		{name: "missing id", cursor: raw("v1:id:1"), sort: "id", wantErr: true},
+		{name: "extra part", cursor: raw("v1:id:1:1:1"), sort: "id", wantErr: true},
+		{name: "key not a number", cursor: raw("v1:id:x:1"), sort: "id", wantErr: true},
+		{name: "id not a number", cursor: raw("v1:id:1:x"), sort: "id", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodePosition(tt.cursor, tt.sort)
+
+			if tt.wantErr {
+				if !errors.Is(err, grpcerror.ErrInvalidCursor) {
+					t.Fatalf("DecodePosition() error = %v, want %v", err, grpcerror.ErrInvalidCursor)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecodePosition() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Fatalf("DecodePosition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// raw encodes the cursor payload as is.
+func raw(payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(payload))
+}
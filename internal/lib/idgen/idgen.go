@@ -0,0 +1,27 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+)
+
+// Generator issues unique positive IDs for the documents of a collection.
+type Generator interface {
+	NextID(ctx context.Context, collection string) (int64, error)
+}
+
+// New returns the generator selected by the configuration. The sequence kind is backed by
+// the storage, so its generator is provided by the repository.
+func New(cfg *config.IDGeneratorConfig, sequence Generator) (Generator, error) {
+	switch cfg.Kind {
+	case config.IDSequence:
+		return sequence, nil
+	case config.IDSnowflake:
+		return NewSnowflake(cfg.NodeID)
+	case config.IDULID:
+		return NewULID(), nil
+	default:
+		return nil, fmt.Errorf("unknown id generator: %q", cfg.Kind)
+	}
+}
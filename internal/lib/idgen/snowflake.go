@@ -0,0 +1,64 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+
+	maxSnowflakeNode     = 1<<snowflakeNodeBits - 1
+	maxSnowflakeSequence = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch is the moment Snowflake timestamps are counted from.
+var snowflakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates time-ordered IDs without coordination between instances:
+// 41 bits of milliseconds since snowflakeEpoch, 10 bits of the node ID and 12 bits of a sequence
+// within the millisecond. Every instance must be configured with a distinct node ID.
+// The IDs of all collections share one sequence. Snowflake is safe for concurrent use.
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastMs   int64
+	sequence int64
+}
+
+// NewSnowflake creates a Snowflake generator for the node, which must be in [0, 1023].
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > maxSnowflakeNode {
+		return nil, fmt.Errorf("snowflake node id must be in [0, %d], got %d", maxSnowflakeNode, node)
+	}
+
+	return &Snowflake{
+		node:   node,
+		lastMs: -1,
+	}, nil
+}
+
+// NextID returns the next ID. If the clock moves backwards or the sequence of a millisecond
+// is exhausted, the IDs keep counting from the last used millisecond, so they never repeat.
+func (s *Snowflake) NextID(_ context.Context, _ string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := time.Since(snowflakeEpoch).Milliseconds()
+
+	switch {
+	case ms > s.lastMs:
+		s.lastMs, s.sequence = ms, 0
+	case s.sequence < maxSnowflakeSequence:
+		s.sequence++
+	default:
+		s.lastMs, s.sequence = s.lastMs+1, 0
+	}
+
+	return s.lastMs<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		s.node<<snowflakeSequenceBits |
+		s.sequence, nil
+}
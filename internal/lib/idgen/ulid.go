@@ -0,0 +1,71 @@
+package idgen
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	ulidRandomBits = 20
+	maxULIDRandom  = 1<<ulidRandomBits - 1
+)
+
+// ULID generates IDs following the ULID layout scaled down to the 63 bits of the int64 IDs
+// used by the API: 43 bits of Unix milliseconds followed by 20 random bits. Like monotonic ULIDs,
+// IDs generated within the same millisecond increment the random part, so they are ordered
+// by the generation time within an instance. Instances need no configuration, but unlike
+// Snowflake IDs two instances may collide with a probability of 2^-20 per millisecond,
+// which the unique indexes of the storage reject.
+// The IDs of all collections share one sequence. ULID is safe for concurrent use.
+type ULID struct {
+	mu     sync.Mutex
+	lastMs int64
+	random int64
+}
+
+// NewULID creates a ULID generator.
+func NewULID() *ULID {
+	return &ULID{
+		lastMs: -1,
+	}
+}
+
+// NextID returns the next ID. If the clock moves backwards or the random part of a millisecond
+// overflows, the IDs keep counting from the last used millisecond, so they never repeat.
+func (u *ULID) NextID(_ context.Context, _ string) (int64, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ms := time.Now().UnixMilli()
+
+	switch {
+	case ms > u.lastMs:
+		random, err := randomBits()
+		if err != nil {
+			return -1, err
+		}
+		u.lastMs, u.random = ms, random
+	case u.random < maxULIDRandom:
+		u.random++
+	default:
+		u.lastMs, u.random = u.lastMs+1, 0
+	}
+
+	return u.lastMs<<ulidRandomBits | u.random, nil
+}
+
+// randomBits returns ulidRandomBits random bits. The top half of the range is left out,
+// so at least half a million IDs can be generated in a millisecond.
+func randomBits() (int64, error) {
+	var b [4]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		return -1, fmt.Errorf("failed to read random bits: %w", err)
+	}
+
+	return int64(binary.BigEndian.Uint32(b[:]) & (maxULIDRandom >> 1)), nil
+}
@@ -0,0 +1,39 @@
+package joincode
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// Length is the number of symbols in a code. Each symbol carries 5 bits, so a code has 60 random bits.
+const Length = 12
+
+// alphabet has 32 symbols and leaves out 0, O, 1 and I, which are easily confused when a code is typed in.
+const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Generate returns a new random code read from crypto/rand.
+func Generate() (string, error) {
+	buf := make([]byte, Length)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	// 256 is a multiple of 32, so every symbol is equally likely
+	for i, b := range buf {
+		buf[i] = alphabet[int(b)%len(alphabet)]
+	}
+
+	return string(buf), nil
+}
+
+// Normalize converts a code typed in by a user to the stored form: letters are upper-cased
+// and the spaces and dashes codes are often split with are removed.
+func Normalize(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
@@ -2,39 +2,60 @@ package jwt
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"slices"
+	"time"
 
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/golang-jwt/jwt"
-	"google.golang.org/grpc/metadata"
 )
 
 type Manager struct {
 	signingKey []byte
+	keys       *KeySet
+	validation Validation
+	parser     *jwt.Parser
+}
+
+// Validation configures the checks of standard claims.
+// Empty Issuers or Audiences lists accept any issuer or audience;
+// Leeway is the allowed clock skew when checking exp, nbf and iat.
+type Validation struct {
+	Issuers       []string
+	Audiences     []string
+	RequireExpiry bool
+	Leeway        time.Duration
 }
 
 // New creates and returns a new instance of the Manager with the provided
-// signing key and tokenTTL.
-func New(signingKey []byte) *Manager {
+// signing key for HMAC tokens, key set for RSA and ECDSA tokens and claims validation rules.
+// An empty signing key disables HMAC tokens, a nil key set disables asymmetric ones.
+func New(signingKey []byte, keys *KeySet, validation Validation) *Manager {
 	return &Manager{
 		signingKey: signingKey,
+		keys:       keys,
+		validation: validation,
+		// time-based claims are validated by validate to take the leeway into account
+		parser: &jwt.Parser{SkipClaimsValidation: true},
 	}
 }
 
-// ParseToken parses the provided JWT token string and validates its signature
-// using the configured signing key. It returns the claims embedded in the token
-// if the signature is valid.
-func (m *Manager) ParseToken(accessToken string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(accessToken, func(tkn *jwt.Token) (interface{}, error) {
-		if _, ok := tkn.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", tkn.Header["alg"]) //nolint
-		}
-		return m.signingKey, nil
+// ParseToken parses the provided JWT token string, validates its signature
+// using the configured signing key or the public key matching the token's kid,
+// and validates its standard claims. It returns the claims embedded in the token
+// if the token is valid. Errors wrap ErrInvalidToken for malformed tokens and
+// ErrTokenExpired, ErrTokenNotValidYet, ErrTokenIssuer or ErrTokenAudience otherwise.
+// ctx bounds the JWKS refresh a token signed with an unknown kid may trigger.
+func (m *Manager) ParseToken(ctx context.Context, accessToken string) (jwt.MapClaims, error) {
+	token, err := m.parser.Parse(accessToken, func(tkn *jwt.Token) (interface{}, error) {
+		return m.keyFunc(ctx, tkn)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("%w: %w", grpcerror.ErrInvalidToken, err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
@@ -43,65 +64,174 @@ func (m *Manager) ParseToken(accessToken string) (jwt.MapClaims, error) {
 	}
 
 	if err = verify(claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", grpcerror.ErrInvalidToken, err)
+	}
+
+	if err = m.validate(claims, time.Now()); err != nil {
 		return nil, err
 	}
 
 	return claims, nil
 }
 
-func verify(claims jwt.MapClaims) error {
-	if _, ok := claims["user_id"]; !ok {
-		return fmt.Errorf("user_id was not found") //nolint
+// validate checks the exp, nbf, iat, iss and aud claims according to the validation rules.
+func (m *Manager) validate(claims jwt.MapClaims, now time.Time) error {
+	leeway := m.validation.Leeway
+
+	exp, ok, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok && m.validation.RequireExpiry {
+		return fmt.Errorf("%w: exp was not found", grpcerror.ErrInvalidToken)
+	}
+	if ok && now.After(exp.Add(leeway)) {
+		return fmt.Errorf("%w: expired at %s", grpcerror.ErrTokenExpired, exp.Format(time.RFC3339))
 	}
 
-	if _, ok := claims["role"]; !ok {
-		return errors.New("role was not found") //nolint
+	nbf, ok, err := timeClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("%w: valid from %s", grpcerror.ErrTokenNotValidYet, nbf.Format(time.RFC3339))
 	}
 
-	if _, ok := claims["email"]; !ok {
-		return errors.New("email was not found") //nolint
+	iat, ok, err := timeClaim(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(leeway).Before(iat) {
+		return fmt.Errorf("%w: issued at %s", grpcerror.ErrTokenNotValidYet, iat.Format(time.RFC3339))
 	}
 
-	if _, ok := claims["user_id"].(float64); !ok {
-		return errors.New("invalid user_id") //nolint
+	if len(m.validation.Issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !slices.Contains(m.validation.Issuers, iss) {
+			return fmt.Errorf("%w: %q", grpcerror.ErrTokenIssuer, iss)
+		}
+	}
+
+	if len(m.validation.Audiences) > 0 {
+		aud := audienceClaim(claims)
+		if !slices.ContainsFunc(aud, func(a string) bool {
+			return slices.Contains(m.validation.Audiences, a)
+		}) {
+			return fmt.Errorf("%w: %q", grpcerror.ErrTokenAudience, aud)
+		}
 	}
 
 	return nil
 }
 
-// GetClaims extracts and returns the JWT claims from the authorization token
-// in the provided context. It relies on the ParseToken method to parse and
-// validate the token's signature.
-func (m *Manager) GetClaims(ctx context.Context) (jwt.MapClaims, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, grpcerror.ErrTokenClaims
+// timeClaim reads a NumericDate claim. The second value reports whether the claim is present.
+func timeClaim(claims jwt.MapClaims, name string) (time.Time, bool, error) {
+	switch v := claims[name].(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case float64:
+		return time.Unix(int64(v), 0), true, nil
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: invalid %s", grpcerror.ErrInvalidToken, name)
+		}
+		return time.Unix(int64(n), 0), true, nil
+	default:
+		return time.Time{}, false, fmt.Errorf("%w: invalid %s", grpcerror.ErrInvalidToken, name)
 	}
-	values := md["authorization"]
-	if len(values) == 0 {
-		return nil, grpcerror.ErrNoToken
+}
+
+// audienceClaim reads the aud claim, which may be either a string or an array of strings.
+func audienceClaim(claims jwt.MapClaims) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		aud := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	default:
+		return nil
 	}
+}
 
-	accessToken := strings.Fields(values[0])[1]
+// keyFunc selects the verification key by the signing method of the token, making sure
+// that the key type matches the method, so a public key is never used as an HMAC secret.
+func (m *Manager) keyFunc(ctx context.Context, tkn *jwt.Token) (interface{}, error) {
+	switch tkn.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(m.signingKey) == 0 {
+			return nil, fmt.Errorf("hmac tokens are not accepted") //nolint
+		}
+		return m.signingKey, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if m.keys == nil {
+			return nil, fmt.Errorf("asymmetric tokens are not accepted") //nolint
+		}
 
-	claims, err := m.ParseToken(accessToken)
-	if err != nil {
-		return nil, err
+		kid, _ := tkn.Header["kid"].(string)
+
+		key, err := m.keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if !keyMatchesMethod(key, tkn.Method) {
+			return nil, fmt.Errorf("key %q does not match signing method %v", kid, tkn.Header["alg"]) //nolint
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", tkn.Header["alg"]) //nolint
 	}
+}
 
-	return claims, nil
+func keyMatchesMethod(key interface{}, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := key.(*ecdsa.PublicKey)
+		return ok
+	default:
+		return false
+	}
 }
 
-func (m *Manager) GetUserIDFromContext(ctx context.Context) int64 {
-	claims, _ := m.GetClaims(ctx)
+func verify(claims jwt.MapClaims) error {
+	if _, ok := claims["user_id"]; !ok {
+		return fmt.Errorf("user_id was not found") //nolint
+	}
 
-	id, _ := claims["user_id"].(float64)
+	if _, ok := claims["role"]; !ok {
+		return errors.New("role was not found") //nolint
+	}
+
+	if _, ok := claims["email"]; !ok {
+		return errors.New("email was not found") //nolint
+	}
 
-	return int64(id)
+	if _, ok := claims["user_id"].(float64); !ok {
+		return errors.New("invalid user_id") //nolint
+	}
+
+	return nil
 }
 
-func (m *Manager) IsAdmin(ctx context.Context) bool {
-	claims, _ := m.GetClaims(ctx)
+// TokenIdentity returns the claims identifying a token for revocation checks:
+// its jti, the owner's ID and the issue time. Missing jti or iat are returned as zero values.
+func TokenIdentity(claims jwt.MapClaims) (jti string, userID int64, issuedAt time.Time) {
+	jti, _ = claims["jti"].(string)
+
+	id, _ := claims["user_id"].(float64)
+
+	issuedAt, _, _ = timeClaim(claims, "iat")
 
-	return claims["role"] == "admin"
+	return jti, int64(id), issuedAt
 }
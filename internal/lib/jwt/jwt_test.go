@@ -0,0 +1,278 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/golang-jwt/jwt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	m := New([]byte("secret"), nil, Validation{
+		Issuers:       []string{"sso"},
+		Audiences:     []string{"family"},
+		RequireExpiry: true,
+		Leeway:        30 * time.Second,
+	})
+
+	// claims returns valid claims with the given ones changed; nil values remove claims.
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"exp": float64(now.Add(time.Hour).Unix()),
+			"iat": float64(now.Add(-time.Minute).Unix()),
+			"iss": "sso",
+			"aud": "family",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+				continue
+			}
+			c[name] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   error
+	}{
+		{
+			name:   "valid",
+			claims: claims(nil),
+		},
+		{
+			name:   "expired",
+			claims: claims(jwt.MapClaims{"exp": float64(now.Add(-time.Minute).Unix())}),
+			want:   grpcerror.ErrTokenExpired,
+		},
+		{
+			name:   "expired within leeway",
+			claims: claims(jwt.MapClaims{"exp": float64(now.Add(-10 * time.Second).Unix())}),
+		},
+		{
+			name:   "not valid yet",
+			claims: claims(jwt.MapClaims{"nbf": float64(now.Add(time.Minute).Unix())}),
+			want:   grpcerror.ErrTokenNotValidYet,
+		},
+		{
+			name:   "issued in the future",
+			claims: claims(jwt.MapClaims{"iat": float64(now.Add(time.Minute).Unix())}),
+			want:   grpcerror.ErrTokenNotValidYet,
+		},
+		{
+			name:   "wrong issuer",
+			claims: claims(jwt.MapClaims{"iss": "other"}),
+			want:   grpcerror.ErrTokenIssuer,
+		},
+		{
+			name:   "missing issuer",
+			claims: claims(jwt.MapClaims{"iss": nil}),
+			want:   grpcerror.ErrTokenIssuer,
+		},
+		{
+			name:   "wrong audience",
+			claims: claims(jwt.MapClaims{"aud": "billing"}),
+			want:   grpcerror.ErrTokenAudience,
+		},
+		{
+			name:   "audience list with accepted audience",
+			claims: claims(jwt.MapClaims{"aud": []interface{}{"billing", "family"}}),
+		},
+		{
+			name:   "missing exp",
+			claims: claims(jwt.MapClaims{"exp": nil}),
+			want:   grpcerror.ErrInvalidToken,
+		},
+		{
+			name:   "malformed exp",
+			claims: claims(jwt.MapClaims{"exp": "tomorrow"}),
+			want:   grpcerror.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.validate(tt.claims, now)
+
+			if tt.want == nil && err != nil {
+				t.Fatalf("validate() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyFunc(t *testing.T) {
+	pemKey := generateRSAKey(t)
+	jwksKey := generateRSAKey(t)
+
+	ks := &KeySet{
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		pemKey:     &pemKey.PublicKey,
+		jwksSource: "jwks.json",
+		keys:       map[string]crypto.PublicKey{"current": &jwksKey.PublicKey},
+		// a recent attempt keeps unknown kids from triggering a refresh
+		attemptedAt: time.Now(),
+	}
+
+	m := New([]byte("secret"), ks, Validation{})
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		kid     string
+		want    interface{}
+		wantErr bool
+		errIs   error
+	}{
+		{
+			name:   "hmac",
+			method: jwt.SigningMethodHS256,
+			want:   []byte("secret"),
+		},
+		{
+			name:   "rsa with known kid",
+			method: jwt.SigningMethodRS256,
+			kid:    "current",
+			want:   &jwksKey.PublicKey,
+		},
+		{
+			name:   "rsa without kid",
+			method: jwt.SigningMethodRS256,
+			want:   &pemKey.PublicKey,
+		},
+		{
+			name:    "rsa with unknown kid",
+			method:  jwt.SigningMethodRS256,
+			kid:     "rotated",
+			wantErr: true,
+			errIs:   ErrUnknownKey,
+		},
+		{
+			name:    "ecdsa with rsa key",
+			method:  jwt.SigningMethodES256,
+			kid:     "current",
+			wantErr: true,
+		},
+		{
+			name:    "none",
+			method:  jwt.SigningMethodNone,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			token := &jwt.Token{
+				Method: tt.method,
+				Header: map[string]interface{}{"alg": tt.method.Alg()},
+			}
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+
+			key, err := m.keyFunc(context.Background(), token)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("keyFunc() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Fatalf("keyFunc() error = %v, want %v", err, tt.errIs)
+			}
+			if !tt.wantErr && !sameKey(key, tt.want) {
+				t.Fatalf("keyFunc() = %v, want %v", key, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseTokenHMACWithPublicKey checks that a token signed with HS256 using the public key
+// as the HMAC secret is rejected, whether HMAC tokens are accepted or not.
+func TestParseTokenHMACWithPublicKey(t *testing.T) {
+	key := generateRSAKey(t)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(1),
+		"role":    "user",
+		"email":   "user@example.com",
+		"exp":     float64(time.Now().Add(time.Hour).Unix()),
+	}).SignedString(public)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	ks := &KeySet{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		pemKey: &key.PublicKey,
+		keys:   map[string]crypto.PublicKey{},
+	}
+
+	tests := []struct {
+		name       string
+		signingKey []byte
+	}{
+		{name: "hmac disabled"},
+		{name: "hmac enabled", signingKey: []byte("secret")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.signingKey, ks, Validation{})
+
+			if _, err := m.ParseToken(context.Background(), token); !errors.Is(err, grpcerror.ErrInvalidToken) {
+				t.Fatalf("ParseToken() error = %v, want %v", err, grpcerror.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	return key
+}
+
+func sameKey(got, want interface{}) bool {
+	switch w := want.(type) {
+	case []byte:
+		g, ok := got.([]byte)
+		return ok && string(g) == string(w)
+	case *rsa.PublicKey:
+		g, ok := got.(*rsa.PublicKey)
+		return ok && g.Equal(w)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,357 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefreshInterval limits how often an unknown kid may trigger a JWKS refresh attempt.
+	minRefreshInterval = time.Minute
+	jwksFetchTimeout   = 10 * time.Second
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet holds public keys used to verify asymmetrically signed tokens.
+// Keys are loaded from a PEM file and/or a JWKS document, which may be either
+// a file path or an HTTP(S) URL. The JWKS document is refreshed periodically and
+// on demand when a token signed with an unknown kid arrives, so keys can be rotated
+// without restarting the service. Concurrent refreshes share a single fetch.
+type KeySet struct {
+	log             *slog.Logger
+	pemKey          crypto.PublicKey
+	jwksSource      string
+	refreshInterval time.Duration
+	mu              sync.RWMutex
+	keys            map[string]crypto.PublicKey
+	attemptedAt     time.Time
+	refreshes       singleflight.Group
+	stopOnce        sync.Once
+	stop            chan struct{}
+	done            chan struct{}
+}
+
+// NewKeySet loads the PEM key from pemPath and the JWKS document from jwksSource.
+// Either of them may be empty. It returns an error if a configured source cannot be loaded.
+func NewKeySet(
+	log *slog.Logger,
+	pemPath string,
+	jwksSource string,
+	refreshInterval time.Duration,
+) (*KeySet, error) {
+	ks := &KeySet{
+		log:             log,
+		jwksSource:      jwksSource,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	if pemPath != "" {
+		key, err := loadPEMKey(pemPath)
+		if err != nil {
+			return nil, err
+		}
+		ks.pemKey = key
+	}
+
+	if jwksSource != "" {
+		if err := ks.Refresh(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+// Key returns the public key with the given kid from the JWKS document. A kid missing from the document
+// triggers a refresh, at most once per minRefreshInterval, and is rejected with ErrUnknownKey if the
+// document still lacks it. Tokens without a kid, and any tokens if no JWKS source is configured,
+// are verified with the PEM key when it is configured.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	attemptedAt := ks.attemptedAt
+	ks.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if kid == "" || ks.jwksSource == "" {
+		if ks.pemKey != nil {
+			return ks.pemKey, nil
+		}
+		return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+	}
+
+	if time.Since(attemptedAt) > minRefreshInterval {
+		if err := ks.Refresh(ctx); err != nil {
+			ks.log.Warn("failed to refresh jwks", sl.Err(err))
+		}
+
+		ks.mu.RLock()
+		key, ok = ks.keys[kid]
+		ks.mu.RUnlock()
+
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+}
+
+// Refresh reloads the JWKS document and replaces the keys it provides. The time of the attempt
+// is recorded whatever its outcome, so a failing source is not retried on every unknown kid.
+// Calls made while a refresh is in progress wait for its result instead of fetching the document again.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ch := ks.refreshes.DoChan("jwks", func() (interface{}, error) {
+		ks.mu.Lock()
+		ks.attemptedAt = time.Now()
+		ks.mu.Unlock()
+
+		data, err := readJWKS(ctx, ks.jwksSource)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+
+		ks.mu.Lock()
+		ks.keys = keys
+		ks.mu.Unlock()
+
+		return nil, nil
+	})
+
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run refreshes the JWKS document every refresh interval until Stop is called.
+// It returns immediately if no JWKS source is configured.
+func (ks *KeySet) Run() {
+	const op = "jwt.KeySet.Run"
+
+	log := ks.log.With(
+		slog.String("op", op),
+	)
+
+	defer close(ks.done)
+
+	if ks.jwksSource == "" || ks.refreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ks.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ks.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if err := ks.Refresh(context.Background()); err != nil {
+			log.Error("failed to refresh jwks", sl.Err(err))
+		}
+	}
+}
+
+// Stop signals the periodic refresh to finish and waits for it. It may be called more than once.
+func (ks *KeySet) Stop() {
+	ks.stopOnce.Do(func() { close(ks.stop) })
+	<-ks.done
+}
+
+func loadPEMKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseECPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return key, nil
+}
+
+func readJWKS(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwks: %w", err)
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks: %w", err)
+	}
+
+	return data, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses RSA and EC signature keys of a JWKS document. Keys of other types are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k *jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k *jwk) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key parameter: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"context"
+
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/golang-jwt/jwt"
+)
+
+const adminRole = "admin"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID int64
+	Email  string
+	Role   string
+}
+
+// IsAdmin reports whether the caller has the admin role.
+func (p Principal) IsAdmin() bool {
+	return p.Role == adminRole
+}
+
+type principalKey struct{}
+
+// PrincipalFromClaims builds the principal from claims validated by ParseToken.
+func PrincipalFromClaims(claims jwt.MapClaims) Principal {
+	id, _ := claims["user_id"].(float64)
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+
+	return Principal{
+		UserID: int64(id),
+		Email:  email,
+		Role:   role,
+	}
+}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal placed into the context by the JWT interceptor.
+// It returns ErrUnauthenticated if the request was not authenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, error) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	if !ok {
+		return Principal{}, grpcerror.ErrUnauthenticated
+	}
+
+	return p, nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// RegisterContactInvite stores a pending invite to the family addressed to the contact and returns its ID.
+// An expired invite of the contact to the family is replaced. If the contact is already invited
+// to the family, it returns ErrInviteExist.
+func (m *MemoryRepository) RegisterContactInvite(
+	ctx context.Context,
+	familyID int64,
+	contact string,
+	inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
+	const op = "invite.memory.RegisterContactInvite"
+
+	defer m.lock(ctx)()
+
+	now := time.Now()
+
+	for id, invite := range m.contacts {
+		if invite.FamilyID != familyID || invite.Contact != contact {
+			continue
+		}
+
+		if now.Before(invite.ExpiresAt) {
+			m.log.With(slog.String("op", op)).Warn(grpcerror.ErrInviteExist.Error())
+			return -1, grpcerror.ErrInviteExist
+		}
+
+		remember(ctx, m, m.contacts, id)
+		delete(m.contacts, id)
+	}
+
+	id, err := m.ids.NextID(ctx, config.ContactInviteCollection)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	remember(ctx, m, m.contacts, id)
+	m.contacts[id] = &models.ContactInvite{
+		ID:        id,
+		FamilyID:  familyID,
+		Contact:   contact,
+		InviterID: inviterID,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	return id, nil
+}
+
+// TakeContactInvites removes and returns the unexpired pending invites addressed to any of the contacts.
+func (m *MemoryRepository) TakeContactInvites(ctx context.Context, contacts []string) ([]models.ContactInvite, error) {
+	var invites []models.ContactInvite
+
+	defer m.lock(ctx)()
+
+	now := time.Now()
+
+	for id, invite := range m.contacts {
+		if slices.Contains(contacts, invite.Contact) && now.Before(invite.ExpiresAt) {
+			invites = append(invites, *invite)
+			remember(ctx, m, m.contacts, id)
+			delete(m.contacts, id)
+		}
+	}
+
+	slices.SortFunc(invites, func(a, b models.ContactInvite) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return invites, nil
+}
+
+// GetFamilyContactInvites retrieves the unexpired pending invites sent to a specific family ordered by their IDs.
+func (m *MemoryRepository) GetFamilyContactInvites(ctx context.Context, familyID int64) ([]models.ContactInvite, error) {
+	var invites []models.ContactInvite
+
+	defer m.rlock(ctx)()
+
+	now := time.Now()
+
+	for _, invite := range m.contacts {
+		if invite.FamilyID == familyID && now.Before(invite.ExpiresAt) {
+			invites = append(invites, *invite)
+		}
+	}
+
+	slices.SortFunc(invites, func(a, b models.ContactInvite) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return invites, nil
+}
+
+// RevokeContactInvite deletes the pending invite with the provided inviteID sent to the family.
+// If the family has no such pending invite, it returns ErrInviteNotFound.
+func (m *MemoryRepository) RevokeContactInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.memory.RevokeContactInvite"
+
+	defer m.lock(ctx)()
+
+	invite, ok := m.contacts[inviteID]
+	if !ok || invite.FamilyID != familyID {
+		m.log.With(slog.String("op", op)).
+			Warn(grpcerror.ErrInviteNotFound.Error(),
+				slog.Int64("family_id", familyID),
+				slog.Int64("pending_invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	remember(ctx, m, m.contacts, inviteID)
+	delete(m.contacts, inviteID)
+
+	return nil
+}
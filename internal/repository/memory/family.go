@@ -0,0 +1,433 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// CreateFamily creates a new family with the specified leader ID and profile.
+func (m *MemoryRepository) CreateFamily(
+	ctx context.Context,
+	leaderID int64,
+	profile models.FamilyProfile,
+) (int64, error) {
+	const op = "family.memory.CreateFamily"
+
+	defer m.lock(ctx)()
+
+	familyID, err := m.ids.NextID(ctx, config.FamilyCollection)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+	m.families[familyID] = &models.Family{
+		ID:           familyID,
+		LeaderUserID: leaderID,
+		MembersID:    []int64{leaderID},
+		Members:      []models.Member{{UserID: leaderID, Role: models.OwnerRole, JoinedAt: now}},
+		Name:         profile.Name,
+		Description:  profile.Description,
+		AvatarURL:    profile.AvatarURL,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	return familyID, nil
+}
+
+// GetFamilyMembersID retrieves the member IDs of the family with the specified ID.
+func (m *MemoryRepository) GetFamilyMembersID(ctx context.Context, familyID int64) ([]int64, error) {
+	const op = "family.memory.GetFamilyMembersID"
+
+	defer m.rlock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return family.MembersID, nil
+}
+
+// GetFamilyLeaderID retrieves the leader's user ID of the family with the specified ID.
+func (m *MemoryRepository) GetFamilyLeaderID(ctx context.Context, familyID int64) (int64, error) {
+	defer m.rlock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return -1, err
+	}
+
+	return family.LeaderUserID, nil
+}
+
+// IsUserInFamily checks whether the specified user is a member of the family with the given ID.
+func (m *MemoryRepository) IsUserInFamily(ctx context.Context, familyID, userID int64) (bool, error) {
+	const op = "family.memory.IsUserInFamily"
+
+	defer m.rlock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return slices.Contains(family.MembersID, userID), nil
+}
+
+// AddUserToFamily adds a user to the specified family.
+// If the user is already a member of the family, it returns ErrUserInFamily.
+func (m *MemoryRepository) AddUserToFamily(ctx context.Context, familyID, userID int64) error {
+	const op = "family.memory.AddUserToFamily"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	if slices.Contains(family.MembersID, userID) {
+		log.Warn(grpcerror.ErrUserInFamily.Error())
+		return grpcerror.ErrUserInFamily
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+	family.MembersID = append(family.MembersID, userID)
+	family.Members = append(family.Members,
+		models.Member{UserID: userID, Role: models.MemberRole, JoinedAt: time.Now()})
+
+	return nil
+}
+
+// RemoveUserFromFamily removes a user from the specified family.
+// If the user is the only member of the family, the whole family is deleted.
+// If the user being removed is the leader of the family, the leadership and the owner role
+// pass to the next available member.
+func (m *MemoryRepository) RemoveUserFromFamily(ctx context.Context, familyID, userID int64) error {
+	const op = "family.memory.RemoveUserFromFamily"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+
+	newMembers := slices.DeleteFunc(family.MembersID, func(i int64) bool {
+		return i == userID
+	})
+
+	if len(newMembers) == 0 {
+		delete(m.families, familyID)
+		return nil
+	}
+
+	family.MembersID = newMembers
+	family.Members = slices.DeleteFunc(family.Members, func(member models.Member) bool {
+		return member.UserID == userID
+	})
+
+	if family.LeaderUserID == userID {
+		family.LeaderUserID = newMembers[0]
+		setRole(family, newMembers[0], models.OwnerRole)
+	}
+
+	return nil
+}
+
+// DeleteFamily deletes the family with the specified ID and returns the IDs of its members.
+// If the family is not found, it returns ErrFamilyNotFound.
+func (m *MemoryRepository) DeleteFamily(ctx context.Context, familyID int64) ([]int64, error) {
+	const op = "family.memory.DeleteFamily"
+
+	defer m.lock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+	delete(m.families, familyID)
+
+	return family.MembersID, nil
+}
+
+// ListFamilies retrieves up to limit families with IDs greater than afterFamilyID ordered by their IDs.
+func (m *MemoryRepository) ListFamilies(ctx context.Context, afterFamilyID int64, limit int) ([]models.Family, error) {
+	defer m.rlock(ctx)()
+
+	families := make([]models.Family, 0, limit)
+
+	for _, family := range m.families {
+		if family.ID > afterFamilyID {
+			families = append(families, copyFamily(family))
+		}
+	}
+
+	slices.SortFunc(families, func(a, b models.Family) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return families[:min(limit, len(families))], nil
+}
+
+// ListUserFamilies retrieves up to limit families of the user with IDs greater than afterFamilyID
+// ordered by their IDs.
+func (m *MemoryRepository) ListUserFamilies(
+	ctx context.Context,
+	userID, afterFamilyID int64,
+	limit int,
+) ([]models.Family, error) {
+	defer m.rlock(ctx)()
+
+	families := make([]models.Family, 0, limit)
+
+	for _, family := range m.families {
+		if family.ID > afterFamilyID && slices.Contains(family.MembersID, userID) {
+			families = append(families, copyFamily(family))
+		}
+	}
+
+	slices.SortFunc(families, func(a, b models.Family) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return families[:min(limit, len(families))], nil
+}
+
+// SearchFamilies retrieves up to limit families passing the filter, sorted as the filter requests,
+// which follow the position after in that order.
+func (m *MemoryRepository) SearchFamilies(
+	ctx context.Context,
+	filter models.FamilyFilter,
+	after *cursor.Position,
+	limit int,
+) ([]models.Family, error) {
+	defer m.rlock(ctx)()
+
+	families := make([]models.Family, 0, limit)
+
+	compare := func(key, id int64, family *models.Family) int {
+		c := cmp.Compare(key, family.SortKey(filter.SortBy))
+		if c == 0 {
+			c = cmp.Compare(id, family.ID)
+		}
+
+		if filter.Descending {
+			return -c
+		}
+		return c
+	}
+
+	for _, family := range m.families {
+		if !filter.Matches(family) {
+			continue
+		}
+
+		if after != nil && compare(after.Key, after.ID, family) >= 0 {
+			continue
+		}
+
+		families = append(families, copyFamily(family))
+	}
+
+	slices.SortFunc(families, func(a, b models.Family) int {
+		return compare(a.SortKey(filter.SortBy), a.ID, &b)
+	})
+
+	return families[:min(limit, len(families))], nil
+}
+
+// GetMemberRole retrieves the role of the user in the specified family.
+// If the user is not a member of the family, it returns ErrUserNotInFamily.
+func (m *MemoryRepository) GetMemberRole(ctx context.Context, familyID, userID int64) (models.FamilyRole, error) {
+	const op = "family.memory.GetMemberRole"
+
+	defer m.rlock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, ok := family.RoleOf(userID)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return role, nil
+}
+
+// SetMemberRole changes the role of the user in the specified family.
+// If the user is not a member of the family, it returns ErrUserNotInFamily.
+func (m *MemoryRepository) SetMemberRole(
+	ctx context.Context,
+	familyID, userID int64,
+	role models.FamilyRole,
+) error {
+	const op = "family.memory.SetMemberRole"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+
+	if !setRole(family, userID, role) {
+		log.Warn(grpcerror.ErrUserNotInFamily.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return nil
+}
+
+// GetFamily retrieves the family with the specified ID.
+func (m *MemoryRepository) GetFamily(ctx context.Context, familyID int64) (models.Family, error) {
+	const op = "family.memory.GetFamily"
+
+	defer m.rlock(ctx)()
+
+	family, err := m.getFamily(familyID)
+	if err != nil {
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return family, nil
+}
+
+// TransferLeadership makes the member toUserID the leader and owner of the family instead of fromUserID,
+// who becomes a co-leader. If fromUserID is no longer the leader, it returns ErrNotLeader;
+// if toUserID is not a member of the family, it returns ErrUserNotInFamily.
+func (m *MemoryRepository) TransferLeadership(ctx context.Context, familyID, fromUserID, toUserID int64) error {
+	const op = "family.memory.TransferLeadership"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	if family.LeaderUserID != fromUserID {
+		log.Warn(grpcerror.ErrNotLeader.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrNotLeader)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+
+	if !setRole(family, toUserID, models.OwnerRole) {
+		log.Warn(grpcerror.ErrUserNotInFamily.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	setRole(family, fromUserID, models.CoLeaderRole)
+	family.LeaderUserID = toUserID
+
+	return nil
+}
+
+// SetSuccessionPolicy changes the succession policy of the specified family.
+func (m *MemoryRepository) SetSuccessionPolicy(
+	ctx context.Context,
+	familyID int64,
+	policy models.SuccessionPolicy,
+) error {
+	const op = "family.memory.SetSuccessionPolicy"
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		m.log.With(slog.String("op", op)).Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+	family.SuccessionPolicy = policy
+
+	return nil
+}
+
+// UpdateFamily changes the profile fields set in the update and the update time of the family.
+func (m *MemoryRepository) UpdateFamily(ctx context.Context, familyID int64, update models.FamilyUpdate) error {
+	const op = "family.memory.UpdateFamily"
+
+	defer m.lock(ctx)()
+
+	family, ok := m.families[familyID]
+	if !ok {
+		m.log.With(slog.String("op", op)).Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	rememberWith(ctx, m, m.families, familyID, copyFamily)
+	update.Apply(family)
+	family.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// setRole changes the role of the member and reports whether the user is a member of the family.
+// The caller must hold the write lock.
+func setRole(family *models.Family, userID int64, role models.FamilyRole) bool {
+	for i := range family.Members {
+		if family.Members[i].UserID == userID {
+			family.Members[i].Role = role
+			return true
+		}
+	}
+
+	return false
+}
+
+// getFamily returns a copy of the family with the specified ID.
+// The caller must hold the lock.
+func (m *MemoryRepository) getFamily(familyID int64) (models.Family, error) {
+	const op = "family.memory.getFamily"
+
+	family, ok := m.families[familyID]
+	if !ok {
+		m.log.With(slog.String("op", op)).Warn(grpcerror.ErrFamilyNotFound.Error())
+		return models.Family{}, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return copyFamily(family), nil
+}
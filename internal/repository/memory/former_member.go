@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"time"
+)
+
+// formerKey identifies a former member of a family.
+type formerKey struct {
+	userID   int64
+	familyID int64
+}
+
+// AddFormerMembers records that the users are not members of the family anymore.
+// A user who left the family before has the time of leaving updated.
+func (m *MemoryRepository) AddFormerMembers(ctx context.Context, familyID int64, userIDs ...int64) error {
+	defer m.lock(ctx)()
+
+	now := time.Now()
+
+	for _, userID := range userIDs {
+		key := formerKey{userID, familyID}
+
+		remember(ctx, m, m.formers, key)
+		m.formers[key] = &models.FormerMember{
+			UserID:   userID,
+			FamilyID: familyID,
+			LeftAt:   now,
+		}
+	}
+
+	return nil
+}
+
+// GetFormerMembers retrieves all recorded former members of families.
+func (m *MemoryRepository) GetFormerMembers(ctx context.Context) ([]models.FormerMember, error) {
+	defer m.rlock(ctx)()
+
+	members := make([]models.FormerMember, 0, len(m.formers))
+
+	for _, member := range m.formers {
+		members = append(members, *member)
+	}
+
+	return members, nil
+}
+
+// DeleteFormerMembers forgets the families the user left before leftBefore.
+func (m *MemoryRepository) DeleteFormerMembers(ctx context.Context, userID int64, leftBefore time.Time) error {
+	defer m.lock(ctx)()
+
+	for key, member := range m.formers {
+		if member.UserID == userID && member.LeftAt.Before(leftBefore) {
+			remember(ctx, m, m.formers, key)
+			delete(m.formers, key)
+		}
+	}
+
+	return nil
+}
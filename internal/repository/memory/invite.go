@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// RegisterInvite registers a new invite with the specified familyID, userID and inviterID expiring at expiresAt
+// and returns the ID of the newly created invite. An expired invite of the user to the family is replaced.
+func (m *MemoryRepository) RegisterInvite(
+	ctx context.Context,
+	familyID, userID, inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
+	const op = "invite.memory.RegisterInvite"
+
+	defer m.lock(ctx)()
+
+	now := time.Now()
+
+	for id, invite := range m.invites {
+		if invite.FamilyID == familyID && invite.UserID == userID && invite.IsExpired(now) {
+			remember(ctx, m, m.invites, id)
+			delete(m.invites, id)
+		}
+	}
+
+	id, err := m.ids.NextID(ctx, config.InviteCollection)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	remember(ctx, m, m.invites, id)
+	m.invites[id] = &models.Invite{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		InviterID: inviterID,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	return id, nil
+}
+
+// GetInvites retrieves unexpired invites for a specific user.
+func (m *MemoryRepository) GetInvites(ctx context.Context, userID int64) ([]models.Invite, error) {
+	var invites []models.Invite
+
+	defer m.rlock(ctx)()
+
+	now := time.Now()
+
+	for _, invite := range m.invites {
+		if invite.UserID == userID && !invite.IsExpired(now) {
+			invites = append(invites, *invite)
+		}
+	}
+
+	slices.SortFunc(invites, func(a, b models.Invite) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return invites, nil
+}
+
+// GetFamilyInvites retrieves unexpired invites to a specific family ordered by their IDs.
+func (m *MemoryRepository) GetFamilyInvites(ctx context.Context, familyID int64) ([]models.Invite, error) {
+	var invites []models.Invite
+
+	defer m.rlock(ctx)()
+
+	now := time.Now()
+
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID && !invite.IsExpired(now) {
+			invites = append(invites, *invite)
+		}
+	}
+
+	slices.SortFunc(invites, func(a, b models.Invite) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return invites, nil
+}
+
+// IsUserInvited checks if a user with a specific ID has an unexpired invite to join a family with a specific ID.
+func (m *MemoryRepository) IsUserInvited(ctx context.Context, familyID, userID int64) (bool, error) {
+	defer m.rlock(ctx)()
+
+	now := time.Now()
+
+	for _, invite := range m.invites {
+		if invite.FamilyID == familyID && invite.UserID == userID && !invite.IsExpired(now) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AcceptInvite removes the invite with the provided inviteID addressed to userID
+// and returns the ID of the family associated with the invite.
+// An expired invite is left for the sweeper and ErrInviteExpired is returned.
+func (m *MemoryRepository) AcceptInvite(ctx context.Context, userID, inviteID int64) (int64, error) {
+	const op = "invite.memory.AcceptInvite"
+
+	defer m.lock(ctx)()
+
+	if invite, ok := m.invites[inviteID]; ok && invite.UserID == userID && invite.IsExpired(time.Now()) {
+		m.log.With(slog.String("op", op)).
+			Warn(grpcerror.ErrInviteExpired.Error(), slog.Int64("invite_id", inviteID))
+		return -1, grpcerror.ErrInviteExpired
+	}
+
+	invite, err := m.takeInvite(ctx, op, userID, inviteID)
+	if err != nil {
+		return -1, err
+	}
+
+	return invite.FamilyID, nil
+}
+
+// DenyInvite removes the invite with the provided inviteID addressed to userID.
+func (m *MemoryRepository) DenyInvite(ctx context.Context, userID, inviteID int64) error {
+	const op = "invite.memory.DenyInvite"
+
+	defer m.lock(ctx)()
+
+	_, err := m.takeInvite(ctx, op, userID, inviteID)
+
+	return err
+}
+
+// RevokeInvite removes the invite with the provided inviteID sent to the family with the provided familyID,
+// whether it is expired or not.
+func (m *MemoryRepository) RevokeInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.memory.RevokeInvite"
+
+	defer m.lock(ctx)()
+
+	invite, ok := m.invites[inviteID]
+	if !ok || invite.FamilyID != familyID {
+		m.log.With(slog.String("op", op)).
+			Warn(grpcerror.ErrInviteNotFound.Error(),
+				slog.Int64("family_id", familyID),
+				slog.Int64("invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	remember(ctx, m, m.invites, inviteID)
+	delete(m.invites, inviteID)
+
+	return nil
+}
+
+// DeleteUserInvites deletes all invites associated with a specific user.
+func (m *MemoryRepository) DeleteUserInvites(ctx context.Context, userID int64) error {
+	defer m.lock(ctx)()
+
+	for id, invite := range m.invites {
+		if invite.UserID == userID {
+			remember(ctx, m, m.invites, id)
+			delete(m.invites, id)
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredInvites deletes the invites, including pending invites addressed to contacts,
+// expired at the moment now and returns their number.
+func (m *MemoryRepository) DeleteExpiredInvites(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+
+	defer m.lock(ctx)()
+
+	for id, invite := range m.invites {
+		if invite.IsExpired(now) {
+			remember(ctx, m, m.invites, id)
+			delete(m.invites, id)
+			deleted++
+		}
+	}
+
+	for id, invite := range m.contacts {
+		if !now.Before(invite.ExpiresAt) {
+			remember(ctx, m, m.contacts, id)
+			delete(m.contacts, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// takeInvite removes and returns the invite with the provided inviteID if it is addressed to userID.
+// The caller must hold the lock.
+func (m *MemoryRepository) takeInvite(
+	ctx context.Context,
+	op string,
+	userID, inviteID int64,
+) (models.Invite, error) {
+	invite, ok := m.invites[inviteID]
+	if !ok || invite.UserID != userID {
+		m.log.With(slog.String("op", op)).
+			Warn(grpcerror.ErrInviteNotFound.Error(),
+				slog.Int64("user_id", userID),
+				slog.Int64("invite_id", inviteID))
+		return models.Invite{}, grpcerror.ErrInviteNotFound
+	}
+
+	remember(ctx, m, m.invites, inviteID)
+	delete(m.invites, inviteID)
+
+	return *invite, nil
+}
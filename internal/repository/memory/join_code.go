@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CreateJoinCode stores a new join code. If the same code is already stored, it returns ErrJoinCodeExist.
+func (m *MemoryRepository) CreateJoinCode(ctx context.Context, code models.JoinCode) error {
+	const op = "joincode.memory.CreateJoinCode"
+
+	defer m.lock(ctx)()
+
+	if _, ok := m.joinCodes[code.Code]; ok {
+		m.log.With(slog.String("op", op)).Warn(grpcerror.ErrJoinCodeExist.Error())
+		return grpcerror.ErrJoinCodeExist
+	}
+
+	remember(ctx, m, m.joinCodes, code.Code)
+	m.joinCodes[code.Code] = &code
+
+	return nil
+}
+
+// GetFamilyJoinCodes retrieves the active join codes of a specific family ordered by their creation time.
+func (m *MemoryRepository) GetFamilyJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error) {
+	var codes []models.JoinCode
+
+	defer m.rlock(ctx)()
+
+	now := time.Now()
+
+	for _, code := range m.joinCodes {
+		if code.FamilyID == familyID && code.IsActive(now) {
+			codes = append(codes, *code)
+		}
+	}
+
+	slices.SortFunc(codes, func(a, b models.JoinCode) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return codes, nil
+}
+
+// DisableJoinCode disables the join code of a specific family, so it can no longer be redeemed.
+// If the family has no such code, it returns ErrJoinCodeNotFound.
+func (m *MemoryRepository) DisableJoinCode(ctx context.Context, familyID int64, code string) error {
+	const op = "joincode.memory.DisableJoinCode"
+
+	defer m.lock(ctx)()
+
+	joinCode, ok := m.joinCodes[code]
+	if !ok || joinCode.FamilyID != familyID {
+		m.log.With(slog.String("op", op)).
+			Warn(grpcerror.ErrJoinCodeNotFound.Error(), slog.Int64("family_id", familyID))
+		return grpcerror.ErrJoinCodeNotFound
+	}
+
+	remember(ctx, m, m.joinCodes, code)
+	joinCode.Disabled = true
+
+	return nil
+}
+
+// RedeemJoinCode counts a use of the active join code and returns the ID of its family.
+// If there is no such active code, it returns ErrJoinCodeNotFound.
+func (m *MemoryRepository) RedeemJoinCode(ctx context.Context, code string) (int64, error) {
+	const op = "joincode.memory.RedeemJoinCode"
+
+	defer m.lock(ctx)()
+
+	joinCode, ok := m.joinCodes[code]
+	if !ok || !joinCode.IsActive(time.Now()) {
+		m.log.With(slog.String("op", op)).Warn(grpcerror.ErrJoinCodeNotFound.Error())
+		return -1, grpcerror.ErrJoinCodeNotFound
+	}
+
+	remember(ctx, m, m.joinCodes, code)
+	joinCode.Uses++
+
+	return joinCode.FamilyID, nil
+}
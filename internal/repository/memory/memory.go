@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/idgen"
+	"log/slog"
+	"sync"
+)
+
+type MemoryRepository struct {
+	mu        sync.RWMutex
+	families  map[int64]*models.Family
+	invites   map[int64]*models.Invite
+	contacts  map[int64]*models.ContactInvite
+	joinCodes map[string]*models.JoinCode
+	outbox    map[int64]*models.OutboxEvent
+	formers   map[formerKey]*models.FormerMember
+	sequences map[string]int64
+	ids       idgen.Generator
+	seq       idgen.Generator
+	log       *slog.Logger
+}
+
+// InitMemoryRepository initializes a new MemoryRepository instance which keeps families, invites,
+// join codes, outbox events, former members and id sequences in process memory. It is safe for concurrent
+// use and mirrors the behaviour of MongoRepository, so the service can be run
+// without an external database. IDs of new entities are issued by the generator selected by idCfg,
+// except for outbox events, which are always numbered by their sequence to keep the order they were stored in.
+func InitMemoryRepository(idCfg *config.IDGeneratorConfig, logger *slog.Logger) (*MemoryRepository, error) {
+	const op = "memory.InitMemoryRepository"
+
+	logger.With(slog.String("op", op)).
+		Warn("using in-memory repository, data will be lost on shutdown")
+
+	repo := &MemoryRepository{
+		families:  make(map[int64]*models.Family),
+		invites:   make(map[int64]*models.Invite),
+		contacts:  make(map[int64]*models.ContactInvite),
+		joinCodes: make(map[string]*models.JoinCode),
+		outbox:    make(map[int64]*models.OutboxEvent),
+		formers:   make(map[formerKey]*models.FormerMember),
+		sequences: make(map[string]int64),
+		log:       logger,
+	}
+
+	repo.seq = sequenceGenerator{repo: repo}
+
+	ids, err := idgen.New(idCfg, repo.seq)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.ids = ids
+
+	return repo, nil
+}
+
+// sequenceGenerator issues IDs from the in-memory sequences, which are rolled back
+// together with the rest of the data by transactions.
+type sequenceGenerator struct {
+	repo *MemoryRepository
+}
+
+// NextID returns the next identifier of the sequence associated with the collection.
+// The caller must hold the write lock.
+func (g sequenceGenerator) NextID(ctx context.Context, collectionName string) (int64, error) {
+	sequences := g.repo.sequences
+	last := sequences[collectionName]
+
+	g.repo.onRollback(ctx, func() { sequences[collectionName] = last })
+
+	sequences[collectionName]++
+	return sequences[collectionName], nil
+}
+
+func copyFamily(family *models.Family) models.Family {
+	return models.Family{
+		ID:               family.ID,
+		LeaderUserID:     family.LeaderUserID,
+		MembersID:        append([]int64(nil), family.MembersID...),
+		Members:          append([]models.Member(nil), family.Members...),
+		SuccessionPolicy: family.SuccessionPolicy,
+		Name:             family.Name,
+		Description:      family.Description,
+		AvatarURL:        family.AvatarURL,
+		CreatedAt:        family.CreatedAt,
+		UpdatedAt:        family.UpdatedAt,
+		MemberCount:      len(family.MembersID),
+	}
+}
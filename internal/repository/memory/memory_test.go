@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/repotest"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// newTestRepository returns an empty repository which discards its logs.
+func newTestRepository(t *testing.T) *MemoryRepository {
+	t.Helper()
+
+	repo, err := InitMemoryRepository(
+		&config.IDGeneratorConfig{Kind: config.IDSequence},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	return repo
+}
+
+func TestContract(t *testing.T) {
+	repotest.Contract(t, func(t *testing.T) repository.Repository {
+		return newTestRepository(t)
+	})
+}
+
+func TestFamilyConcurrency(t *testing.T) {
+	repotest.FamilyConcurrency(t, func(t *testing.T) repository.FamilyRepository {
+		return newTestRepository(t)
+	})
+}
+
+// TestOutboxSequence checks that outbox events are numbered by their sequence,
+// which keeps their delivery order, whatever generator issues the IDs of other entities.
+func TestOutboxSequence(t *testing.T) {
+	for _, kind := range []string{config.IDSequence, config.IDSnowflake, config.IDULID} {
+		kind := kind
+
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+
+			repo, err := InitMemoryRepository(
+				&config.IDGeneratorConfig{Kind: kind, NodeID: 1},
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+			)
+			if err != nil {
+				t.Fatalf("failed to create repository: %v", err)
+			}
+
+			err = repo.AddOutboxEvents(ctx,
+				models.NewOutboxEvent(models.AddFamilyAction, 1, 1),
+				models.NewOutboxEvent(models.AddFamilyAction, 2, 1))
+			if err != nil {
+				t.Fatalf("AddOutboxEvents() error = %v", err)
+			}
+
+			for want := int64(1); want <= 2; want++ {
+				event, ok, err := repo.ClaimOutboxEvent(ctx, "test", time.Now().UTC().Add(time.Minute), time.Minute)
+				if err != nil || !ok {
+					t.Fatalf("ClaimOutboxEvent() = %v, %v; want an event", ok, err)
+				}
+				if event.ID != want {
+					t.Fatalf("event ID = %d, want %d", event.ID, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"slices"
+	"time"
+)
+
+// AddOutboxEvents assigns identifiers to the provided events and stores them. The identifiers are taken
+// from the outbox sequence whatever the configured ID generator is, since events are delivered in their order.
+func (m *MemoryRepository) AddOutboxEvents(ctx context.Context, events ...models.OutboxEvent) error {
+	const op = "outbox.memory.AddOutboxEvents"
+
+	defer m.lock(ctx)()
+
+	for _, event := range events {
+		event := event
+
+		id, err := m.seq.NextID(ctx, config.OutboxCollection)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		event.ID = id
+		remember(ctx, m, m.outbox, event.ID)
+		m.outbox[event.ID] = &event
+	}
+
+	return nil
+}
+
+// ClaimOutboxEvent leases the oldest event which is due for delivery at the moment now to owner
+// for the lease duration and returns it. Events of the same user and family are applied in the order
+// they were stored, so an event is not claimed while an earlier one of the pair is waiting for delivery.
+// Leased and dead-lettered events are skipped. The second result is false if there is no event to claim.
+func (m *MemoryRepository) ClaimOutboxEvent(
+	ctx context.Context,
+	owner string,
+	now time.Time,
+	lease time.Duration,
+) (models.OutboxEvent, bool, error) {
+	defer m.lock(ctx)()
+
+	ids := make([]int64, 0, len(m.outbox))
+	for id := range m.outbox {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	type key struct{ userID, familyID int64 }
+
+	blocked := make(map[key]bool)
+
+	for _, id := range ids {
+		event := m.outbox[id]
+		if event.DeadLetteredAt != nil {
+			continue
+		}
+
+		k := key{event.UserID, event.FamilyID}
+		if blocked[k] {
+			continue
+		}
+		blocked[k] = true
+
+		if event.NextAttemptAt.After(now) || event.IsLocked(now) {
+			continue
+		}
+
+		lockedUntil := now.Add(lease)
+
+		remember(ctx, m, m.outbox, id)
+		event.Owner = owner
+		event.LockedUntil = &lockedUntil
+
+		return *event, true, nil
+	}
+
+	return models.OutboxEvent{}, false, nil
+}
+
+// RescheduleOutboxEvent stores the number of failed delivery attempts of the event and the time of the next one
+// and releases its lease. Nothing is changed if the event is not leased to owner anymore.
+func (m *MemoryRepository) RescheduleOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	next time.Time,
+) error {
+	defer m.lock(ctx)()
+
+	if event, ok := m.outbox[eventID]; ok && event.Owner == owner {
+		remember(ctx, m, m.outbox, eventID)
+		event.Attempts = attempts
+		event.NextAttemptAt = next
+		event.Owner = ""
+		event.LockedUntil = nil
+	}
+
+	return nil
+}
+
+// DeadLetterOutboxEvent stops the delivery of the event leased to owner and keeps it with the error
+// of its last attempt. Later events of the same user and family are not held back by it.
+func (m *MemoryRepository) DeadLetterOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	reason string,
+) error {
+	defer m.lock(ctx)()
+
+	if event, ok := m.outbox[eventID]; ok && event.Owner == owner {
+		now := time.Now().UTC()
+
+		remember(ctx, m, m.outbox, eventID)
+		event.Attempts = attempts
+		event.DeadLetteredAt = &now
+		event.LastError = reason
+		event.Owner = ""
+		event.LockedUntil = nil
+	}
+
+	return nil
+}
+
+// DeleteOutboxEvent removes a delivered event.
+func (m *MemoryRepository) DeleteOutboxEvent(ctx context.Context, eventID int64) error {
+	defer m.lock(ctx)()
+
+	remember(ctx, m, m.outbox, eventID)
+	delete(m.outbox, eventID)
+
+	return nil
+}
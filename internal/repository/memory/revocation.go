@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps token revocations in process memory.
+// Revocations are lost on restart and are not shared between instances of the service.
+type RevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[int64]time.Time
+}
+
+func NewRevocationStore() *RevocationStore {
+	return &RevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[int64]time.Time),
+	}
+}
+
+// RevokeToken revokes the token with the given jti until it expires.
+// Revocations of already expired tokens are dropped at the same time.
+func (s *RevocationStore) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for id, exp := range s.tokens {
+		if exp.Before(now) {
+			delete(s.tokens, id)
+		}
+	}
+
+	s.tokens[jti] = expiresAt
+
+	return nil
+}
+
+// RevokeUserTokens revokes all tokens of the user issued before issuedBefore.
+// An earlier moment never overrides a later one.
+func (s *RevocationStore) RevokeUserTokens(_ context.Context, userID int64, issuedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if issuedBefore.After(s.users[userID]) {
+		s.users[userID] = issuedBefore
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks whether the token with the given jti, owner and issue time is revoked.
+// A zero issuedAt is treated as unknown, so the token is revoked if its owner has any revocation.
+// Otherwise the token is revoked if it was issued strictly before the revocation moment of its owner.
+func (s *RevocationStore) IsTokenRevoked(_ context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if exp, ok := s.tokens[jti]; ok && jti != "" && exp.After(time.Now()) {
+		return true, nil
+	}
+
+	before, ok := s.users[userID]
+
+	return ok && (issuedAt.IsZero() || issuedAt.Before(before)), nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+)
+
+type txKey struct{}
+
+// txLog is the undo log of a transaction: the functions reverting its changes,
+// in the order they were made.
+type txLog struct {
+	repo *MemoryRepository
+	undo []func()
+}
+
+// WithinTransaction runs fn while holding the repository lock exclusively, so other
+// callers observe either none or all of its changes. The previous state of every entry
+// fn changes is recorded, and if fn returns an error, the touched entries are restored
+// in reverse order, so a rollback costs as much as the changes made by fn.
+// Calls made with a context which already carries a transaction join it.
+func (m *MemoryRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &txLog{repo: m}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// lock acquires the write lock unless ctx belongs to a transaction which already holds it.
+// It returns the function releasing the lock.
+func (m *MemoryRepository) lock(ctx context.Context) func() {
+	if m.inTx(ctx) {
+		return func() {}
+	}
+
+	m.mu.Lock()
+
+	return m.mu.Unlock
+}
+
+// rlock acquires the read lock unless ctx belongs to a transaction which already holds the write lock.
+// It returns the function releasing the lock.
+func (m *MemoryRepository) rlock(ctx context.Context) func() {
+	if m.inTx(ctx) {
+		return func() {}
+	}
+
+	m.mu.RLock()
+
+	return m.mu.RUnlock
+}
+
+func (m *MemoryRepository) inTx(ctx context.Context) bool {
+	return m.tx(ctx) != nil
+}
+
+// tx returns the transaction of the repository ctx belongs to or nil.
+func (m *MemoryRepository) tx(ctx context.Context) *txLog {
+	tx, _ := ctx.Value(txKey{}).(*txLog)
+	if tx == nil || tx.repo != m {
+		return nil
+	}
+
+	return tx
+}
+
+// onRollback registers undo to be called if the transaction of ctx is rolled back.
+// Outside of a transaction it does nothing.
+func (m *MemoryRepository) onRollback(ctx context.Context, undo func()) {
+	if tx := m.tx(ctx); tx != nil {
+		tx.undo = append(tx.undo, undo)
+	}
+}
+
+// remember records the current state of data[key], so it is restored if the transaction of ctx
+// is rolled back. It has to be called before the entry is added, replaced, deleted or changed
+// through its pointer. The caller must hold the write lock.
+func remember[K comparable, V any](ctx context.Context, m *MemoryRepository, data map[K]*V, key K) {
+	rememberWith(ctx, m, data, key, func(v *V) V { return *v })
+}
+
+// rememberWith is remember for values which share memory with their shallow copies;
+// clone has to return a copy of the value which is not changed when the value is.
+func rememberWith[K comparable, V any](
+	ctx context.Context,
+	m *MemoryRepository,
+	data map[K]*V,
+	key K,
+	clone func(*V) V,
+) {
+	tx := m.tx(ctx)
+	if tx == nil {
+		return
+	}
+
+	old, ok := data[key]
+	if !ok {
+		tx.undo = append(tx.undo, func() { delete(data, key) })
+		return
+	}
+
+	saved := clone(old)
+	tx.undo = append(tx.undo, func() { data[key] = &saved })
+}
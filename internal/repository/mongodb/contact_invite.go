@@ -0,0 +1,190 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"time"
+)
+
+// RegisterContactInvite stores a pending invite to the family addressed to the contact and returns its ID.
+// An expired invite of the contact to the family, not yet removed by the TTL index, is deleted first.
+// If the contact is already invited to the family, the unique index rejects the invite and it returns ErrInviteExist.
+func (m *MongoRepository) RegisterContactInvite(
+	ctx context.Context,
+	familyID int64,
+	contact string,
+	inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
+	const op = "invite.mongo.RegisterContactInvite"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	collName := m.Config.Collections[config.ContactInviteCollection]
+	coll := m.Db.Database(m.Config.DBName).Collection(collName)
+
+	now := time.Now()
+
+	expiredFilter := bson.D{
+		{"contact", contact},
+		{"family_id", familyID},
+		{"expires_at", bson.D{{"$lte", now}}},
+	}
+
+	if _, err := coll.DeleteOne(ctx, expiredFilter); err != nil {
+		log.Error("failed to delete expired contact invite", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := m.ids.NextID(ctx, collName)
+	if err != nil {
+		log.Error("failed to get new id for contact invite", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invite := models.ContactInvite{
+		ID:        id,
+		FamilyID:  familyID,
+		Contact:   contact,
+		InviterID: inviterID,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	_, err = coll.InsertOne(ctx, invite)
+	if mongo.IsDuplicateKeyError(err) {
+		log.Warn(grpcerror.ErrInviteExist.Error())
+		return -1, grpcerror.ErrInviteExist
+	}
+	if err != nil {
+		log.Error("failed to insert contact invite into db", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// TakeContactInvites removes and returns the unexpired pending invites addressed to any of the contacts.
+// Run in a transaction, the invites are removed only if it is committed.
+func (m *MongoRepository) TakeContactInvites(ctx context.Context, contacts []string) ([]models.ContactInvite, error) {
+	const op = "invite.mongo.TakeContactInvites"
+
+	var invites []models.ContactInvite
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.ContactInviteCollection])
+
+	filter := bson.D{
+		{"contact", bson.D{{"$in", contacts}}},
+		notExpired(time.Now()),
+	}
+
+	cur, err := coll.Find(ctx, filter)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &invites); err != nil {
+		log.Error("failed to decode contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(invites) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(invites))
+	for _, invite := range invites {
+		ids = append(ids, invite.ID)
+	}
+
+	_, err = coll.DeleteMany(ctx, bson.D{{"contact_invite_id", bson.D{{"$in", ids}}}})
+	if err != nil {
+		log.Error("failed to delete contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// GetFamilyContactInvites retrieves the unexpired pending invites sent to a specific family ordered by their IDs.
+func (m *MongoRepository) GetFamilyContactInvites(ctx context.Context, familyID int64) ([]models.ContactInvite, error) {
+	const op = "invite.mongo.GetFamilyContactInvites"
+
+	var invites []models.ContactInvite
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.ContactInviteCollection])
+
+	filter := bson.D{
+		{"family_id", familyID},
+		notExpired(time.Now()),
+	}
+
+	opts := options.Find().SetSort(bson.D{{"contact_invite_id", 1}})
+
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &invites); err != nil {
+		log.Error("failed to decode contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// RevokeContactInvite deletes the pending invite with the provided inviteID sent to the family.
+// If the family has no such pending invite, it returns ErrInviteNotFound.
+func (m *MongoRepository) RevokeContactInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.mongo.RevokeContactInvite"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.ContactInviteCollection])
+
+	filter := bson.D{
+		{"contact_invite_id", inviteID},
+		{"family_id", familyID},
+	}
+
+	res, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Error("failed to delete contact invite", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.DeletedCount == 0 {
+		log.Warn(grpcerror.ErrInviteNotFound.Error(),
+			slog.Int64("family_id", familyID),
+			slog.Int64("pending_invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	return nil
+}
@@ -7,15 +7,21 @@ import (
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log/slog"
-	"slices"
+	"time"
 )
 
-// CreateFamily creates a new family in the database with the specified leader ID.
-func (m *MongoRepository) CreateFamily(ctx context.Context, leaderID int64) (int64, error) {
+// CreateFamily creates a new family in the database with the specified leader ID and profile.
+func (m *MongoRepository) CreateFamily(
+	ctx context.Context,
+	leaderID int64,
+	profile models.FamilyProfile,
+) (int64, error) {
 	const op = "family.mongo.CreateFamily"
 
 	log := m.log.With(
@@ -25,16 +31,25 @@ func (m *MongoRepository) CreateFamily(ctx context.Context, leaderID int64) (int
 	coll := m.Db.Database(m.Config.DBName).Collection(
 		m.Config.Collections[config.FamilyCollection])
 
-	familyID, err := m.getNewID(ctx, m.Config.Collections[config.FamilyCollection])
+	familyID, err := m.ids.NextID(ctx, m.Config.Collections[config.FamilyCollection])
 	if err != nil {
 		log.Error("failed to generate new family id", sl.Err(err))
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
 
+	now := time.Now()
+
 	family := models.Family{
 		ID:           familyID,
 		LeaderUserID: leaderID,
 		MembersID:    []int64{leaderID},
+		Members:      []models.Member{{UserID: leaderID, Role: models.OwnerRole, JoinedAt: now}},
+		Name:         profile.Name,
+		Description:  profile.Description,
+		AvatarURL:    profile.AvatarURL,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		MemberCount:  1,
 	}
 
 	_, err = coll.InsertOne(ctx, family)
@@ -89,10 +104,12 @@ func (m *MongoRepository) IsUserInFamily(ctx context.Context, familyID, userID i
 }
 
 // AddUserToFamily adds a user to the specified family.
-// It first checks if the user is already a member of the family using IsUserInFamily method.
-// If the user is already in the family, it returns an error indicating that the user is already a member.
-// Otherwise, it retrieves the current list of family members using GetFamilyMembersID method,
-// appends the new user ID to the list, and updates the family document in the database.
+// The user is appended to the members array by a single conditional update which
+// only matches the family if the user is not a member yet, so concurrent calls
+// cannot overwrite each other's changes. A family without members is never matched:
+// its last member has left and RemoveUserFromFamily is about to delete it.
+// If nothing was updated, it checks the family to return either
+// ErrFamilyNotFound or ErrUserInFamily.
 func (m *MongoRepository) AddUserToFamily(ctx context.Context, familyID, userID int64) error {
 	const op = "family.mongo.AddUserToFamily"
 
@@ -100,51 +117,58 @@ func (m *MongoRepository) AddUserToFamily(ctx context.Context, familyID, userID
 		slog.String("op", op),
 	)
 
-	inFamily, err := m.IsUserInFamily(ctx, familyID, userID)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	if inFamily {
-		log.Warn(grpcerror.ErrUserInFamily.Error())
-		return grpcerror.ErrUserInFamily
-	}
-
-	members, err := m.GetFamilyMembersID(ctx, familyID)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	members = append(members, userID)
-
 	coll := m.Db.Database(m.Config.DBName).Collection(
 		m.Config.Collections[config.FamilyCollection])
 
 	filter := bson.D{
 		{"family_id", familyID},
+		{"members", bson.D{{"$ne", userID}}},
+		{"members.0", bson.D{{"$exists", true}}},
 	}
 
 	update := bson.D{
-		{"$set", bson.D{
-			{"members", members},
+		{"$push", bson.D{
+			{"members", userID},
+			{"member_details", models.Member{UserID: userID, Role: models.MemberRole, JoinedAt: time.Now()}},
+		},
+		},
+		{"$inc", bson.D{
+			{"member_count", 1},
 		},
 		},
 	}
 
-	_, err = coll.UpdateOne(ctx, filter, update)
+	res, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		log.Error("failed to update family in db", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if res.MatchedCount == 0 {
+		family, err := m.getFamily(ctx, familyID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if len(family.MembersID) == 0 {
+			log.Warn(grpcerror.ErrFamilyNotFound.Error())
+			return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+		}
+
+		log.Warn(grpcerror.ErrUserInFamily.Error())
+		return grpcerror.ErrUserInFamily
+	}
+
 	return nil
 }
 
 // RemoveUserFromFamily removes a user from the specified family.
-// It retrieves the family document from the database using the familyID.
-// If the user is the only member of the family, it deletes the entire family document from the database.
-// If the user is not the only member, it removes the user from the family members list and updates the family document.
-// If the user being removed is the leader of the family, it updates the leader to the next available member.
+// The user is pulled from the members array by a single pipeline update which also
+// passes the leadership and the owner role to the next available member if the user being
+// removed is the leader, so concurrent calls cannot overwrite each other's changes.
+// If the user was the only member of the family, the family document is deleted.
+// AddUserToFamily does not match families without members, so nobody can join
+// the family between the update and the deletion.
 func (m *MongoRepository) RemoveUserFromFamily(ctx context.Context, familyID, userID int64) error {
 	const op = "family.mongo.RemoveUserFromFamily"
 
@@ -152,46 +176,80 @@ func (m *MongoRepository) RemoveUserFromFamily(ctx context.Context, familyID, us
 		slog.String("op", op),
 	)
 
-	family, err := m.getFamily(ctx, familyID)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	newMembers := slices.DeleteFunc(family.MembersID, func(i int64) bool {
-		return i == userID
-	})
-
 	coll := m.Db.Database(m.Config.DBName).Collection(
 		m.Config.Collections[config.FamilyCollection])
 
 	filter := bson.D{
 		{"family_id", familyID},
+		{"members", userID},
 	}
 
-	if len(newMembers) == 0 {
-		_, err = coll.DeleteOne(ctx, filter)
-		if err != nil {
-			log.Error("failed to delete family", sl.Err(err))
+	remaining := bson.D{
+		{"$filter", bson.D{
+			{"input", "$members"},
+			{"cond", bson.D{{"$ne", bson.A{"$$this", userID}}}},
+		}},
+	}
+
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"members", remaining},
+			{"member_count", bson.D{{"$size", remaining}}},
+			{"leader_id", bson.D{
+				{"$cond", bson.A{
+					bson.D{{"$eq", bson.A{"$leader_id", userID}}},
+					bson.D{{"$ifNull", bson.A{
+						bson.D{{"$arrayElemAt", bson.A{remaining, 0}}},
+						"$leader_id",
+					}}},
+					"$leader_id",
+				}},
+			}},
+		}}},
+		// runs after leader_id is updated, so the new leader becomes the owner
+		{{"$set", bson.D{
+			{"member_details", bson.D{
+				{"$map", bson.D{
+					{"input", bson.D{
+						{"$filter", bson.D{
+							{"input", "$member_details"},
+							{"cond", bson.D{{"$ne", bson.A{"$$this.user_id", userID}}}},
+						}},
+					}},
+					{"in", bson.D{
+						{"$cond", bson.A{
+							bson.D{{"$eq", bson.A{"$$this.user_id", "$leader_id"}}},
+							bson.D{{"$mergeObjects", bson.A{"$$this", bson.D{{"role", models.OwnerRole}}}}},
+							"$$this",
+						}},
+					}},
+				}},
+			}},
+		}}},
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.MatchedCount == 0 {
+		if _, err = m.getFamily(ctx, familyID); err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
-		return nil
-	}
 
-	if family.LeaderUserID == userID {
-		family.LeaderUserID = newMembers[0]
+		return nil
 	}
 
-	update := bson.D{
-		{"$set", bson.D{
-			{"members", newMembers},
-			{"leader_id", family.LeaderUserID},
-		},
-		},
+	emptyFilter := bson.D{
+		{"family_id", familyID},
+		{"members", bson.D{{"$size", 0}}},
 	}
 
-	_, err = coll.UpdateOne(ctx, filter, update)
+	_, err = coll.DeleteOne(ctx, emptyFilter)
 	if err != nil {
-		log.Error("failed to update family in db", sl.Err(err))
+		log.Error("failed to delete family", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -237,10 +295,204 @@ func (m *MongoRepository) DeleteFamily(ctx context.Context, familyID int64) ([]i
 	return family.MembersID, nil
 }
 
-func (m *MongoRepository) getFamily(ctx context.Context, familyID int64) (models.Family, error) {
-	const op = "family.mongo.getFamily"
+// ListFamilies retrieves up to limit families with IDs greater than afterFamilyID ordered by their IDs.
+func (m *MongoRepository) ListFamilies(ctx context.Context, afterFamilyID int64, limit int) ([]models.Family, error) {
+	const op = "family.mongo.ListFamilies"
 
-	var family models.Family
+	var families []models.Family
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"family_id", bson.D{{"$gt", afterFamilyID}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{"family_id", 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &families); err != nil {
+		log.Error("failed to decode families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// ListUserFamilies retrieves up to limit families of the user with IDs greater than afterFamilyID
+// ordered by their IDs. The query is served by the (members, family_id) index.
+func (m *MongoRepository) ListUserFamilies(
+	ctx context.Context,
+	userID, afterFamilyID int64,
+	limit int,
+) ([]models.Family, error) {
+	const op = "family.mongo.ListUserFamilies"
+
+	var families []models.Family
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"members", userID},
+		{"family_id", bson.D{{"$gt", afterFamilyID}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{"family_id", 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &families); err != nil {
+		log.Error("failed to decode families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// SearchFamilies retrieves up to limit families passing the filter, sorted as the filter requests,
+// which follow the position after in that order. Every sort field has an index with family_id
+// breaking the ties, and the leader and member filters are served by their own indexes.
+func (m *MongoRepository) SearchFamilies(
+	ctx context.Context,
+	filter models.FamilyFilter,
+	after *cursor.Position,
+	limit int,
+) ([]models.Family, error) {
+	const op = "family.mongo.SearchFamilies"
+
+	var families []models.Family
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	query := bson.D{}
+
+	if filter.LeaderID != 0 {
+		query = append(query, bson.E{"leader_id", filter.LeaderID})
+	}
+	if filter.MemberID != 0 {
+		query = append(query, bson.E{"members", filter.MemberID})
+	}
+
+	count := bson.D{}
+	if filter.MinMembers > 0 {
+		count = append(count, bson.E{"$gte", filter.MinMembers})
+	}
+	if filter.MaxMembers > 0 {
+		count = append(count, bson.E{"$lte", filter.MaxMembers})
+	}
+	if len(count) > 0 {
+		query = append(query, bson.E{"member_count", count})
+	}
+
+	created := bson.D{}
+	if !filter.CreatedAfter.IsZero() {
+		created = append(created, bson.E{"$gte", filter.CreatedAfter})
+	}
+	if !filter.CreatedBefore.IsZero() {
+		created = append(created, bson.E{"$lt", filter.CreatedBefore})
+	}
+	if len(created) > 0 {
+		query = append(query, bson.E{"created_at", created})
+	}
+
+	field, order, next := string(filter.SortBy), 1, "$gt"
+	if filter.Descending {
+		order, next = -1, "$lt"
+	}
+
+	if after != nil {
+		var key any = after.Key
+		if filter.SortBy == models.SortByCreatedAt {
+			key = time.UnixMicro(after.Key)
+		}
+
+		if filter.SortBy == models.SortByID {
+			query = append(query, bson.E{"family_id", bson.D{{next, after.ID}}})
+		} else {
+			query = append(query, bson.E{"$or", bson.A{
+				bson.D{{field, bson.D{{next, key}}}},
+				bson.D{{field, key}, {"family_id", bson.D{{next, after.ID}}}},
+			}})
+		}
+	}
+
+	sort := bson.D{{field, order}}
+	if filter.SortBy != models.SortByID {
+		sort = append(sort, bson.E{"family_id", order})
+	}
+
+	opts := options.Find().
+		SetSort(sort).
+		SetLimit(int64(limit))
+
+	cur, err := coll.Find(ctx, query, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &families); err != nil {
+		log.Error("failed to decode families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// GetMemberRole retrieves the role of the user in the specified family.
+// If the user is not a member of the family, it returns ErrUserNotInFamily.
+func (m *MongoRepository) GetMemberRole(ctx context.Context, familyID, userID int64) (models.FamilyRole, error) {
+	const op = "family.mongo.GetMemberRole"
+
+	family, err := m.getFamily(ctx, familyID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, ok := family.RoleOf(userID)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return role, nil
+}
+
+// SetMemberRole changes the role of the user in the specified family.
+// If nothing was updated, it checks whether the family exists to return either
+// ErrFamilyNotFound or ErrUserNotInFamily.
+func (m *MongoRepository) SetMemberRole(
+	ctx context.Context,
+	familyID, userID int64,
+	role models.FamilyRole,
+) error {
+	const op = "family.mongo.SetMemberRole"
 
 	log := m.log.With(
 		slog.String("op", op),
@@ -251,48 +503,225 @@ func (m *MongoRepository) getFamily(ctx context.Context, familyID int64) (models
 
 	filter := bson.D{
 		{"family_id", familyID},
+		{"member_details.user_id", userID},
 	}
 
-	res := coll.FindOne(ctx, filter)
-	if res.Err() != nil {
-		log.Warn(grpcerror.ErrFamilyNotFound.Error())
-		return models.Family{}, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	update := bson.D{
+		{"$set", bson.D{
+			{"member_details.$.role", role},
+		},
+		},
 	}
 
-	if err := res.Decode(&family); err != nil {
-		log.Error("failed to decode family", sl.Err(err))
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.MatchedCount == 0 {
+		if _, err = m.getFamily(ctx, familyID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Warn(grpcerror.ErrUserNotInFamily.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return nil
+}
+
+// GetFamily retrieves the family with the specified ID from the database.
+func (m *MongoRepository) GetFamily(ctx context.Context, familyID int64) (models.Family, error) {
+	const op = "family.mongo.GetFamily"
+
+	family, err := m.getFamily(ctx, familyID)
+	if err != nil {
 		return models.Family{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return family, nil
 }
 
-func (m *MongoRepository) getNewID(ctx context.Context, collectionName string) (int64, error) {
-	var seq models.Sequence
+// TransferLeadership makes the member toUserID the leader and owner of the family instead of fromUserID,
+// who becomes a co-leader. The change is made by a single pipeline update which only matches the family
+// if fromUserID is still its leader and toUserID is its member. If nothing was updated, it checks
+// the family to return ErrFamilyNotFound, ErrNotLeader or ErrUserNotInFamily.
+func (m *MongoRepository) TransferLeadership(ctx context.Context, familyID, fromUserID, toUserID int64) error {
+	const op = "family.mongo.TransferLeadership"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"family_id", familyID},
+		{"leader_id", fromUserID},
+		{"members", toUserID},
+	}
+
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"leader_id", toUserID},
+			{"member_details", bson.D{
+				{"$map", bson.D{
+					{"input", "$member_details"},
+					{"in", bson.D{
+						{"$switch", bson.D{
+							{"branches", bson.A{
+								bson.D{
+									{"case", bson.D{{"$eq", bson.A{"$$this.user_id", toUserID}}}},
+									{"then", bson.D{{"$mergeObjects", bson.A{"$$this", bson.D{{"role", models.OwnerRole}}}}}},
+								},
+								bson.D{
+									{"case", bson.D{{"$eq", bson.A{"$$this.user_id", fromUserID}}}},
+									{"then", bson.D{{"$mergeObjects", bson.A{"$$this", bson.D{{"role", models.CoLeaderRole}}}}}},
+								},
+							}},
+							{"default", "$$this"},
+						}},
+					}},
+				}},
+			}},
+		}}},
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.MatchedCount == 0 {
+		family, err := m.getFamily(ctx, familyID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if family.LeaderUserID != fromUserID {
+			log.Warn(grpcerror.ErrNotLeader.Error())
+			return fmt.Errorf("%s: %w", op, grpcerror.ErrNotLeader)
+		}
+
+		log.Warn(grpcerror.ErrUserNotInFamily.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return nil
+}
+
+// SetSuccessionPolicy changes the succession policy of the specified family.
+func (m *MongoRepository) SetSuccessionPolicy(
+	ctx context.Context,
+	familyID int64,
+	policy models.SuccessionPolicy,
+) error {
+	const op = "family.mongo.SetSuccessionPolicy"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
 
 	coll := m.Db.Database(m.Config.DBName).Collection(
-		m.Config.Collections[config.SequenceCollection])
+		m.Config.Collections[config.FamilyCollection])
 
 	filter := bson.D{
-		{"collection_name", collectionName},
+		{"family_id", familyID},
 	}
 
 	update := bson.D{
-		{"$inc", bson.D{
-			{"counter", 1},
+		{"$set", bson.D{
+			{"succession_policy", policy},
 		},
 		},
 	}
 
-	res := coll.FindOneAndUpdate(ctx, filter, update)
-	if res.Err() != nil {
-		return -1, fmt.Errorf("failed to get id: %w", res.Err())
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	err := res.Decode(&seq)
+	if res.MatchedCount == 0 {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return nil
+}
+
+// UpdateFamily changes the profile fields set in the update and the update time of the family.
+func (m *MongoRepository) UpdateFamily(ctx context.Context, familyID int64, update models.FamilyUpdate) error {
+	const op = "family.mongo.UpdateFamily"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	fields := bson.D{
+		{"updated_at", time.Now()},
+	}
+
+	if update.Name != nil {
+		fields = append(fields, bson.E{"name", *update.Name})
+	}
+	if update.Description != nil {
+		fields = append(fields, bson.E{"description", *update.Description})
+	}
+	if update.AvatarURL != nil {
+		fields = append(fields, bson.E{"avatar_url", *update.AvatarURL})
+	}
+
+	filter := bson.D{
+		{"family_id", familyID},
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, bson.D{{"$set", fields}})
 	if err != nil {
-		return -1, fmt.Errorf("failed to decode sequence: %w", err)
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return seq.Counter, nil
+	if res.MatchedCount == 0 {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return nil
+}
+
+func (m *MongoRepository) getFamily(ctx context.Context, familyID int64) (models.Family, error) {
+	const op = "family.mongo.getFamily"
+
+	var family models.Family
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"family_id", familyID},
+	}
+
+	res := coll.FindOne(ctx, filter)
+	if res.Err() != nil {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return models.Family{}, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	if err := res.Decode(&family); err != nil {
+		log.Error("failed to decode family", sl.Err(err))
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return family, nil
 }
@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log/slog"
+	"time"
+)
+
+// AddFormerMembers records that the users are not members of the family anymore.
+// A user who left the family before has the time of leaving updated.
+func (m *MongoRepository) AddFormerMembers(ctx context.Context, familyID int64, userIDs ...int64) error {
+	const op = "formermember.mongo.AddFormerMembers"
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FormerMemberCollection])
+
+	now := time.Now()
+
+	writes := make([]mongo.WriteModel, 0, len(userIDs))
+
+	for _, userID := range userIDs {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{"user_id", userID}, {"family_id", familyID}}).
+			SetUpdate(bson.D{{"$set", bson.D{{"left_at", now}}}}).
+			SetUpsert(true))
+	}
+
+	_, err := coll.BulkWrite(ctx, writes)
+	if err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to store former members", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetFormerMembers retrieves all recorded former members of families.
+func (m *MongoRepository) GetFormerMembers(ctx context.Context) ([]models.FormerMember, error) {
+	const op = "formermember.mongo.GetFormerMembers"
+
+	var members []models.FormerMember
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FormerMemberCollection])
+
+	cur, err := coll.Find(ctx, bson.D{})
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &members); err != nil {
+		log.Error("failed to decode former members", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// DeleteFormerMembers forgets the families the user left before leftBefore.
+func (m *MongoRepository) DeleteFormerMembers(ctx context.Context, userID int64, leftBefore time.Time) error {
+	const op = "formermember.mongo.DeleteFormerMembers"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FormerMemberCollection])
+
+	filter := bson.D{
+		{"user_id", userID},
+		{"left_at", bson.D{{"$lt", leftBefore}}},
+	}
+
+	_, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to delete former members", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
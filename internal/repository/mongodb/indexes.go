@@ -0,0 +1,225 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"strings"
+)
+
+// namespaceNotFound is the code of the error listing indexes of a collection that does not exist yet.
+const namespaceNotFound = 26
+
+// indexSpec is an index the repository relies on. Collection is the key of the collection in the config.
+// A TTL index removes documents once the time in its field has passed. A sparse index skips documents
+// without its fields, so a sparse unique index allows any number of them.
+type indexSpec struct {
+	Collection string
+	Name       string
+	Keys       bson.D
+	Unique     bool
+	Sparse     bool
+	TTL        bool
+}
+
+// requiredIndexes are created on start if missing. Unique indexes make duplicate documents
+// impossible at the storage level rather than relying on checks made before inserts.
+var requiredIndexes = []indexSpec{
+	{Collection: config.FamilyCollection, Name: "family_id_unique", Keys: bson.D{{"family_id", 1}}, Unique: true},
+	{Collection: config.FamilyCollection, Name: "members_family_id", Keys: bson.D{{"members", 1}, {"family_id", 1}}},
+	{Collection: config.FamilyCollection, Name: "leader_id_family_id", Keys: bson.D{{"leader_id", 1}, {"family_id", 1}}},
+	{Collection: config.FamilyCollection, Name: "created_at_family_id", Keys: bson.D{{"created_at", 1}, {"family_id", 1}}},
+	{Collection: config.FamilyCollection, Name: "member_count_family_id", Keys: bson.D{{"member_count", 1}, {"family_id", 1}}},
+	{Collection: config.InviteCollection, Name: "invite_id_unique", Keys: bson.D{{"invite_id", 1}}, Unique: true},
+	{Collection: config.InviteCollection, Name: "family_id_user_id_unique", Keys: bson.D{{"family_id", 1}, {"user_id", 1}}, Unique: true},
+	{Collection: config.InviteCollection, Name: "user_id", Keys: bson.D{{"user_id", 1}}},
+	{Collection: config.InviteCollection, Name: "expires_at_ttl", Keys: bson.D{{"expires_at", 1}}, TTL: true},
+	{Collection: config.ContactInviteCollection, Name: "contact_invite_id_unique", Keys: bson.D{{"contact_invite_id", 1}}, Unique: true},
+	{Collection: config.ContactInviteCollection, Name: "contact_family_id_unique", Keys: bson.D{{"contact", 1}, {"family_id", 1}}, Unique: true},
+	{Collection: config.ContactInviteCollection, Name: "family_id_contact_invite_id", Keys: bson.D{{"family_id", 1}, {"contact_invite_id", 1}}},
+	{Collection: config.ContactInviteCollection, Name: "expires_at_ttl", Keys: bson.D{{"expires_at", 1}}, TTL: true},
+	{Collection: config.JoinCodeCollection, Name: "code_unique", Keys: bson.D{{"code", 1}}, Unique: true},
+	{Collection: config.JoinCodeCollection, Name: "family_id_created_at", Keys: bson.D{{"family_id", 1}, {"created_at", 1}}},
+	{Collection: config.SequenceCollection, Name: "collection_name_unique", Keys: bson.D{{"collection_name", 1}}, Unique: true},
+	{Collection: config.OutboxCollection, Name: "event_id_unique", Keys: bson.D{{"event_id", 1}}, Unique: true},
+	{Collection: config.OutboxCollection, Name: "user_id_family_id_event_id", Keys: bson.D{{"user_id", 1}, {"family_id", 1}, {"event_id", 1}}},
+	{Collection: config.FormerMemberCollection, Name: "user_id_family_id_unique", Keys: bson.D{{"user_id", 1}, {"family_id", 1}}, Unique: true},
+	{Collection: config.RevocationCollection, Name: "jti_unique", Keys: bson.D{{"jti", 1}}, Unique: true, Sparse: true},
+	{Collection: config.RevocationCollection, Name: "user_id_unique", Keys: bson.D{{"user_id", 1}}, Unique: true, Sparse: true},
+	{Collection: config.RevocationCollection, Name: "expires_at_ttl", Keys: bson.D{{"expires_at", 1}}, TTL: true},
+}
+
+// revocationIndexes are the required indexes of the token revocation store.
+func revocationIndexes() []indexSpec {
+	var specs []indexSpec
+
+	for _, spec := range requiredIndexes {
+		if spec.Collection == config.RevocationCollection {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs
+}
+
+// existingIndex is an index as listed by the database.
+type existingIndex struct {
+	Name        string `bson:"name"`
+	Keys        bson.D `bson:"key"`
+	Unique      bool   `bson:"unique"`
+	Sparse      bool   `bson:"sparse"`
+	ExpireAfter *int32 `bson:"expireAfterSeconds"`
+}
+
+// ensureIndexes creates the missing indexes of the given ones and reports index drift: required indexes
+// existing with other keys or uniqueness and indexes which are not required. Drifted indexes
+// are left untouched, since rebuilding them may lock the collection or fail on duplicates,
+// and have to be fixed by an operator.
+// Creating a unique index fails if the collection already holds duplicates, which stops the start.
+func (m *MongoRepository) ensureIndexes(ctx context.Context, indexes []indexSpec) error {
+	const op = "mongo.ensureIndexes"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	specs := make(map[string][]indexSpec)
+	for _, spec := range indexes {
+		specs[spec.Collection] = append(specs[spec.Collection], spec)
+	}
+
+	for collection, required := range specs {
+		name := m.Config.Collections[collection]
+		coll := m.Db.Database(m.Config.DBName).Collection(name)
+
+		existing, err := listIndexes(ctx, coll)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", op, name, err)
+		}
+
+		var missing []mongo.IndexModel
+
+		for _, spec := range required {
+			index, ok := findIndex(existing, spec)
+			if !ok {
+				opts := options.Index().SetName(spec.Name).SetUnique(spec.Unique).SetSparse(spec.Sparse)
+				if spec.TTL {
+					opts.SetExpireAfterSeconds(0)
+				}
+
+				missing = append(missing, mongo.IndexModel{
+					Keys:    spec.Keys,
+					Options: opts,
+				})
+				continue
+			}
+
+			ttl := index.ExpireAfter != nil && *index.ExpireAfter == 0
+			if keysString(index.Keys) != keysString(spec.Keys) || index.Unique != spec.Unique ||
+				index.Sparse != spec.Sparse || ttl != spec.TTL {
+				log.Warn("index drift: index differs from the required one",
+					slog.String("collection", name),
+					slog.String("index", index.Name),
+					slog.String("keys", keysString(index.Keys)),
+					slog.Bool("unique", index.Unique),
+					slog.Bool("sparse", index.Sparse),
+					slog.Bool("ttl", ttl),
+					slog.String("required_keys", keysString(spec.Keys)),
+					slog.Bool("required_unique", spec.Unique),
+					slog.Bool("required_sparse", spec.Sparse),
+					slog.Bool("required_ttl", spec.TTL))
+			}
+		}
+
+		for _, index := range existing {
+			if index.Name == "_id_" || isRequired(required, index) {
+				continue
+			}
+
+			log.Warn("index drift: index is not required",
+				slog.String("collection", name),
+				slog.String("index", index.Name),
+				slog.String("keys", keysString(index.Keys)))
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		created, err := coll.Indexes().CreateMany(ctx, missing)
+		if err != nil {
+			return fmt.Errorf("%s: failed to create indexes of %s: %w", op, name, err)
+		}
+
+		log.Info("indexes created",
+			slog.String("collection", name),
+			slog.Any("indexes", created))
+	}
+
+	return nil
+}
+
+// listIndexes returns the indexes of the collection. A collection that does not exist has none.
+func listIndexes(ctx context.Context, coll *mongo.Collection) ([]existingIndex, error) {
+	var indexes []existingIndex
+
+	cur, err := coll.Indexes().List(ctx)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == namespaceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cur.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
+
+// findIndex looks the required index up by its name and then by its keys,
+// so indexes created by hand under other names are not duplicated.
+func findIndex(existing []existingIndex, spec indexSpec) (existingIndex, bool) {
+	for _, index := range existing {
+		if index.Name == spec.Name {
+			return index, true
+		}
+	}
+
+	for _, index := range existing {
+		if keysString(index.Keys) == keysString(spec.Keys) {
+			return index, true
+		}
+	}
+
+	return existingIndex{}, false
+}
+
+func isRequired(required []indexSpec, index existingIndex) bool {
+	for _, spec := range required {
+		if index.Name == spec.Name || keysString(index.Keys) == keysString(spec.Keys) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keysString formats index keys as "field:order,...". Orders are printed the same
+// whatever numeric type the database returned them in.
+func keysString(keys bson.D) string {
+	fields := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		fields = append(fields, fmt.Sprintf("%s:%v", key.Key, key.Value))
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -10,13 +10,27 @@ import (
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log/slog"
+	"time"
 )
 
+// notExpired matches invites which have not expired at the moment now,
+// including invites without expires_at, which never expire.
+func notExpired(now time.Time) bson.E {
+	return bson.E{"expires_at", bson.D{{"$not", bson.D{{"$lte", now}}}}}
+}
+
 // RegisterInvite registers a new invite in the database.
-// It creates a new invite document with the specified familyID and userID,
+// It creates a new invite document with the specified familyID, userID and inviterID expiring at expiresAt,
 // inserts it into the database, and returns the ID of the newly created invite.
-func (m *MongoRepository) RegisterInvite(ctx context.Context, familyID, userID int64) (int64, error) {
+// An expired invite of the user to the family, not yet removed by the TTL index, is deleted first.
+// If the user is already invited to the family, the unique index rejects the invite and it returns ErrInviteExist.
+func (m *MongoRepository) RegisterInvite(
+	ctx context.Context,
+	familyID, userID, inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
 	const op = "invite.mongo.RegisterInvite"
 
 	log := m.log.With(
@@ -26,19 +40,40 @@ func (m *MongoRepository) RegisterInvite(ctx context.Context, familyID, userID i
 	coll := m.Db.Database(m.Config.DBName).Collection(
 		m.Config.Collections[config.InviteCollection])
 
-	id, err := m.getNewID(ctx, m.Config.Collections[config.InviteCollection])
+	now := time.Now()
+
+	expiredFilter := bson.D{
+		{"family_id", familyID},
+		{"user_id", userID},
+		{"expires_at", bson.D{{"$lte", now}}},
+	}
+
+	_, err := coll.DeleteOne(ctx, expiredFilter)
+	if err != nil {
+		log.Error("failed to delete expired invite", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := m.ids.NextID(ctx, m.Config.Collections[config.InviteCollection])
 	if err != nil {
 		log.Error("failed to get new id for invite", sl.Err(err))
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
 
 	invite := models.Invite{
-		ID:       id,
-		FamilyID: familyID,
-		UserID:   userID,
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		InviterID: inviterID,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
 	}
 
 	_, err = coll.InsertOne(ctx, invite)
+	if mongo.IsDuplicateKeyError(err) {
+		log.Warn(grpcerror.ErrInviteExist.Error())
+		return -1, grpcerror.ErrInviteExist
+	}
 	if err != nil {
 		log.Error("failed to insert new invite into db", sl.Err(err))
 		return -1, fmt.Errorf("%s: %w", op, err)
@@ -47,7 +82,7 @@ func (m *MongoRepository) RegisterInvite(ctx context.Context, familyID, userID i
 	return id, nil
 }
 
-// GetInvites retrieves invites for a specific user from the database.
+// GetInvites retrieves unexpired invites for a specific user from the database.
 // It searches the database for invites associated with the specified userID,
 // retrieves them, and returns a slice of models.Invite.
 func (m *MongoRepository) GetInvites(ctx context.Context, userID int64) ([]models.Invite, error) {
@@ -64,6 +99,7 @@ func (m *MongoRepository) GetInvites(ctx context.Context, userID int64) ([]model
 
 	filter := bson.D{
 		{"user_id", userID},
+		notExpired(time.Now()),
 	}
 
 	cur, err := coll.Find(ctx, filter)
@@ -80,8 +116,42 @@ func (m *MongoRepository) GetInvites(ctx context.Context, userID int64) ([]model
 	return invites, nil
 }
 
+// GetFamilyInvites retrieves unexpired invites to a specific family from the database ordered by their IDs.
+func (m *MongoRepository) GetFamilyInvites(ctx context.Context, familyID int64) ([]models.Invite, error) {
+	const op = "invite.mongo.GetFamilyInvites"
+
+	var invites []models.Invite
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.InviteCollection])
+
+	filter := bson.D{
+		{"family_id", familyID},
+		notExpired(time.Now()),
+	}
+
+	opts := options.Find().SetSort(bson.D{{"invite_id", 1}})
+
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &invites); err != nil {
+		log.Error("failed to decode invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
 // IsUserInvited checks if a user with a specific ID is invited to join a family with a specific ID.
-// It searches the database for an invitation matching the provided familyID and userID.
+// It searches the database for an unexpired invitation matching the provided familyID and userID.
 // If an invitation is found, it returns true, indicating that the user is invited.
 func (m *MongoRepository) IsUserInvited(ctx context.Context, familyID, userID int64) (bool, error) {
 	const op = "invite.mongo.IsUserInvited"
@@ -96,6 +166,7 @@ func (m *MongoRepository) IsUserInvited(ctx context.Context, familyID, userID in
 	filter := bson.D{
 		{"family_id", familyID},
 		{"user_id", userID},
+		notExpired(time.Now()),
 	}
 
 	res := coll.FindOne(ctx, filter)
@@ -113,6 +184,7 @@ func (m *MongoRepository) IsUserInvited(ctx context.Context, familyID, userID in
 // AcceptInvite accepts an invitation for a specific user.
 // It searches for an invitation in the database with the provided userID and inviteID.
 // If an invitation is found, it removes the invite from the database and returns the ID of the family associated with the invite.
+// An expired invitation is not removed and ErrInviteExpired is returned.
 func (m *MongoRepository) AcceptInvite(ctx context.Context, userID, inviteID int64) (int64, error) {
 	const op = "invite.mongo.AcceptInvite"
 
@@ -130,8 +202,19 @@ func (m *MongoRepository) AcceptInvite(ctx context.Context, userID, inviteID int
 		{"user_id", userID},
 	}
 
-	res := coll.FindOneAndDelete(ctx, filter)
+	res := coll.FindOneAndDelete(ctx, append(filter, notExpired(time.Now())))
 	if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+		err := coll.FindOne(ctx, filter).Err()
+		if err == nil {
+			log.Warn(grpcerror.ErrInviteExpired.Error(),
+				slog.Int64("invite_id", inviteID))
+			return -1, grpcerror.ErrInviteExpired
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Error("failed to search in db", sl.Err(err))
+			return -1, fmt.Errorf("%s: %w", op, err)
+		}
+
 		log.Warn(grpcerror.ErrInviteNotFound.Error(),
 			slog.Int64("user_id", userID),
 			slog.Int64("invite_id", inviteID))
@@ -183,6 +266,39 @@ func (m *MongoRepository) DenyInvite(ctx context.Context, userID, inviteID int64
 	return nil
 }
 
+// RevokeInvite removes the invite with the provided inviteID sent to the family with the provided familyID.
+// Expired invites can be revoked as well. If there is no such invite, it returns ErrInviteNotFound.
+func (m *MongoRepository) RevokeInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.mongo.RevokeInvite"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.InviteCollection])
+
+	filter := bson.D{
+		{"invite_id", inviteID},
+		{"family_id", familyID},
+	}
+
+	res, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Error("failed to delete invite", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.DeletedCount == 0 {
+		log.Warn(grpcerror.ErrInviteNotFound.Error(),
+			slog.Int64("family_id", familyID),
+			slog.Int64("invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	return nil
+}
+
 // DeleteUserInvites deletes all invites associated with a specific user.
 // It searches for invites in the database with the provided userID and deletes them.
 func (m *MongoRepository) DeleteUserInvites(ctx context.Context, userID int64) error {
@@ -207,3 +323,30 @@ func (m *MongoRepository) DeleteUserInvites(ctx context.Context, userID int64) e
 
 	return nil
 }
+
+// DeleteExpiredInvites deletes the invites, including pending invites addressed to contacts,
+// expired at the moment now and returns their number.
+// Expired invites are removed by the TTL index anyway, but its monitor runs only once a minute.
+func (m *MongoRepository) DeleteExpiredInvites(ctx context.Context, now time.Time) (int64, error) {
+	const op = "invite.mongo.DeleteExpiredInvites"
+
+	var deleted int64
+
+	filter := bson.D{
+		{"expires_at", bson.D{{"$lte", now}}},
+	}
+
+	for _, collection := range []string{config.InviteCollection, config.ContactInviteCollection} {
+		coll := m.Db.Database(m.Config.DBName).Collection(m.Config.Collections[collection])
+
+		res, err := coll.DeleteMany(ctx, filter)
+		if err != nil {
+			m.log.With(slog.String("op", op)).Error("failed to delete expired invites", sl.Err(err))
+			return deleted, fmt.Errorf("%s: %w", op, err)
+		}
+
+		deleted += res.DeletedCount
+	}
+
+	return deleted, nil
+}
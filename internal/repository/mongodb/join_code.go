@@ -0,0 +1,153 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"time"
+)
+
+// activeJoinCode matches join codes which can be redeemed at the moment now.
+func activeJoinCode(now time.Time) bson.D {
+	return bson.D{
+		{"disabled", false},
+		notExpired(now),
+		{"$expr", bson.D{{"$or", bson.A{
+			bson.D{{"$eq", bson.A{"$max_uses", 0}}},
+			bson.D{{"$lt", bson.A{"$uses", "$max_uses"}}},
+		}}}},
+	}
+}
+
+// CreateJoinCode stores a new join code. If the same code is already stored,
+// the unique index rejects it and ErrJoinCodeExist is returned.
+func (m *MongoRepository) CreateJoinCode(ctx context.Context, code models.JoinCode) error {
+	const op = "joincode.mongo.CreateJoinCode"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.JoinCodeCollection])
+
+	_, err := coll.InsertOne(ctx, code)
+	if mongo.IsDuplicateKeyError(err) {
+		log.Warn(grpcerror.ErrJoinCodeExist.Error())
+		return grpcerror.ErrJoinCodeExist
+	}
+	if err != nil {
+		log.Error("failed to insert join code into db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetFamilyJoinCodes retrieves the active join codes of a specific family ordered by their creation time.
+func (m *MongoRepository) GetFamilyJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error) {
+	const op = "joincode.mongo.GetFamilyJoinCodes"
+
+	var codes []models.JoinCode
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.JoinCodeCollection])
+
+	filter := append(bson.D{{"family_id", familyID}}, activeJoinCode(time.Now())...)
+
+	opts := options.Find().SetSort(bson.D{{"created_at", 1}, {"code", 1}})
+
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = cur.All(ctx, &codes); err != nil {
+		log.Error("failed to decode join codes", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// DisableJoinCode disables the join code of a specific family, so it can no longer be redeemed.
+// If the family has no such code, it returns ErrJoinCodeNotFound.
+func (m *MongoRepository) DisableJoinCode(ctx context.Context, familyID int64, code string) error {
+	const op = "joincode.mongo.DisableJoinCode"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.JoinCodeCollection])
+
+	filter := bson.D{
+		{"code", code},
+		{"family_id", familyID},
+	}
+
+	update := bson.D{
+		{"$set", bson.D{{"disabled", true}}},
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error("failed to disable join code", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.MatchedCount == 0 {
+		log.Warn(grpcerror.ErrJoinCodeNotFound.Error(), slog.Int64("family_id", familyID))
+		return grpcerror.ErrJoinCodeNotFound
+	}
+
+	return nil
+}
+
+// RedeemJoinCode counts a use of the active join code and returns the ID of its family.
+// The code is checked and its uses are incremented in one atomic update, so a code is never
+// redeemed more times than allowed. If there is no such active code, it returns ErrJoinCodeNotFound.
+func (m *MongoRepository) RedeemJoinCode(ctx context.Context, code string) (int64, error) {
+	const op = "joincode.mongo.RedeemJoinCode"
+
+	var joinCode models.JoinCode
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.JoinCodeCollection])
+
+	filter := append(bson.D{{"code", code}}, activeJoinCode(time.Now())...)
+
+	update := bson.D{
+		{"$inc", bson.D{{"uses", 1}}},
+	}
+
+	err := coll.FindOneAndUpdate(ctx, filter, update).Decode(&joinCode)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		log.Warn(grpcerror.ErrJoinCodeNotFound.Error())
+		return -1, grpcerror.ErrJoinCodeNotFound
+	}
+	if err != nil {
+		log.Error("failed to redeem join code", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return joinCode.FamilyID, nil
+}
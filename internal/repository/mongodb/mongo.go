@@ -4,25 +4,77 @@ import (
 	"context"
 	"fmt"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/idgen"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log/slog"
+	"time"
 )
 
 type MongoRepository struct {
 	Db     *mongo.Client
 	Config *config.MongoConfig
 	log    *slog.Logger
+	ids    idgen.Generator
+	seq    idgen.Generator
 }
 
 // InitMongoRepository initializes a new MongoRepository instance with the provided
 // configuration, logger, and hash salt. It establishes a connection to the MongoDB
 // server, performs a ping to ensure connectivity, and returns the initialized
-// MongoRepository instance.
-func InitMongoRepository(cfg *config.MongoConfig, logger *slog.Logger) (
+// MongoRepository instance. IDs of new documents are issued by the generator selected
+// by idCfg, except for outbox events, which are always numbered by their sequence. Pending data migrations are applied and missing indexes are created before returning.
+func InitMongoRepository(cfg *config.MongoConfig, idCfg *config.IDGeneratorConfig, logger *slog.Logger) (
 	*MongoRepository, error) {
-	const op = "mongo.InitMongoRepository"
+	repo, err := connect(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.seq = &sequenceGenerator{repo: repo}
+
+	repo.ids, err = idgen.New(idCfg, repo.seq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = repo.migrateMemberRoles(context.TODO()); err != nil {
+		return nil, fmt.Errorf("failed to migrate member roles: %w", err)
+	}
+
+	if err = repo.migrateFamilyFields(context.TODO()); err != nil {
+		return nil, fmt.Errorf("failed to migrate family fields: %w", err)
+	}
+
+	if err = repo.ensureIndexes(context.TODO(), requiredIndexes); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return repo, nil
+}
+
+// InitRevocationStore connects to MongoDB for the token revocation store only,
+// for when the main repository lives in another storage. Unlike InitMongoRepository
+// it runs no migrations and sets up no ID generator; only the indexes
+// of the revocation collection are created.
+func InitRevocationStore(cfg *config.MongoConfig, logger *slog.Logger) (*MongoRepository, error) {
+	repo, err := connect(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = repo.ensureIndexes(context.TODO(), revocationIndexes()); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return repo, nil
+}
+
+// connect establishes a connection to the MongoDB server and pings it.
+func connect(cfg *config.MongoConfig, logger *slog.Logger) (*MongoRepository, error) {
+	const op = "mongo.connect"
 
 	log := logger.With(
 		slog.String("op", op),
@@ -53,3 +105,85 @@ func InitMongoRepository(cfg *config.MongoConfig, logger *slog.Logger) (
 		log:    logger,
 	}, nil
 }
+
+// migrateMemberRoles fills member_details of families created before family roles were introduced:
+// the leader becomes the owner and everyone else a plain member. Migrated families are skipped,
+// so it is safe to run on every start.
+func (m *MongoRepository) migrateMemberRoles(ctx context.Context) error {
+	const op = "mongo.migrateMemberRoles"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"member_details", bson.D{{"$exists", false}}},
+	}
+
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"member_details", bson.D{
+				{"$map", bson.D{
+					{"input", "$members"},
+					{"in", bson.D{
+						{"user_id", "$$this"},
+						{"role", bson.D{
+							{"$cond", bson.A{
+								bson.D{{"$eq", bson.A{"$$this", "$leader_id"}}},
+								models.OwnerRole,
+								models.MemberRole,
+							}},
+						}},
+					}},
+				}},
+			}},
+		}}},
+	}
+
+	res, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.ModifiedCount > 0 {
+		m.log.With(slog.String("op", op)).
+			Info("member roles migrated", slog.Int64("families", res.ModifiedCount))
+	}
+
+	return nil
+}
+
+// migrateFamilyFields fills the fields families are searched by in families created before they were stored:
+// member_count is counted from members and a missing created_at is set to the zero time, so such families
+// sort before all others. Migrated families are skipped, so it is safe to run on every start.
+func (m *MongoRepository) migrateFamilyFields(ctx context.Context) error {
+	const op = "mongo.migrateFamilyFields"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.FamilyCollection])
+
+	filter := bson.D{
+		{"$or", bson.A{
+			bson.D{{"member_count", bson.D{{"$exists", false}}}},
+			bson.D{{"created_at", bson.D{{"$exists", false}}}},
+		}},
+	}
+
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"member_count", bson.D{{"$size", "$members"}}},
+			{"created_at", bson.D{{"$ifNull", bson.A{"$created_at", time.Time{}}}}},
+		}}},
+	}
+
+	res, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if res.ModifiedCount > 0 {
+		m.log.With(slog.String("op", op)).
+			Info("family fields migrated", slog.Int64("families", res.ModifiedCount))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/idgen"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/repotest"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestRepository connects to the MongoDB server at MONGO_TEST_URI and returns a repository
+// using a database of its own, which is dropped when the test finishes.
+// The test is skipped if MONGO_TEST_URI is not set.
+func newTestRepository(t *testing.T) *MongoRepository {
+	t.Helper()
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("failed to connect to mongo: %v", err)
+	}
+
+	repo := &MongoRepository{
+		Db: db,
+		Config: &config.MongoConfig{
+			DBName: fmt.Sprintf("family_test_%d", time.Now().UnixNano()),
+			Collections: map[string]string{
+				config.FamilyCollection:        "family",
+				config.InviteCollection:        "invite",
+				config.SequenceCollection:      "sequence",
+				config.OutboxCollection:        "outbox",
+				config.RevocationCollection:    "revocation",
+				config.JoinCodeCollection:      "join_code",
+				config.ContactInviteCollection: "contact_invite",
+				config.FormerMemberCollection:  "former_member",
+			},
+		},
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	repo.seq = &sequenceGenerator{repo: repo}
+
+	repo.ids, err = idgen.New(&config.IDGeneratorConfig{Kind: config.IDSequence}, repo.seq)
+	if err != nil {
+		t.Fatalf("failed to create id generator: %v", err)
+	}
+
+	if err = repo.ensureIndexes(ctx, requiredIndexes); err != nil {
+		t.Fatalf("failed to create indexes: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = db.Database(repo.Config.DBName).Drop(ctx)
+		_ = db.Disconnect(ctx)
+	})
+
+	return repo
+}
+
+func TestContract(t *testing.T) {
+	repotest.Contract(t, func(t *testing.T) repository.Repository {
+		return newTestRepository(t)
+	})
+}
+
+func TestFamilyConcurrency(t *testing.T) {
+	repotest.FamilyConcurrency(t, func(t *testing.T) repository.FamilyRepository {
+		return newTestRepository(t)
+	})
+}
@@ -0,0 +1,236 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"time"
+)
+
+// AddOutboxEvents assigns identifiers to the provided events and inserts them into the outbox collection.
+// The identifiers are taken from the outbox sequence whatever the configured ID generator is: events are
+// delivered in the order of their IDs, which Snowflake or ULID-style IDs of skewed instances would not keep.
+func (m *MongoRepository) AddOutboxEvents(ctx context.Context, events ...models.OutboxEvent) error {
+	const op = "outbox.mongo.AddOutboxEvents"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.OutboxCollection])
+
+	docs := make([]interface{}, 0, len(events))
+
+	for _, event := range events {
+		id, err := m.seq.NextID(ctx, m.Config.Collections[config.OutboxCollection])
+		if err != nil {
+			log.Error("failed to get new id for outbox event", sl.Err(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		event.ID = id
+		docs = append(docs, event)
+	}
+
+	_, err := coll.InsertMany(ctx, docs)
+	if err != nil {
+		log.Error("failed to insert outbox events into db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimOutboxEvent leases the oldest event which is due for delivery at the moment now to owner
+// for the lease duration and returns it. Events are claimed one by one in the order of the event_id index
+// with FindOneAndUpdate, so concurrent instances never claim the same event. Events of the same user and family
+// are applied in the order they were stored: a claimed event with an earlier live event of its pair
+// is released again and the search goes on after it. The second result is false if there is no event to claim.
+func (m *MongoRepository) ClaimOutboxEvent(
+	ctx context.Context,
+	owner string,
+	now time.Time,
+	lease time.Duration,
+) (models.OutboxEvent, bool, error) {
+	const op = "outbox.mongo.ClaimOutboxEvent"
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.OutboxCollection])
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{"event_id", 1}}).
+		SetReturnDocument(options.After)
+
+	var after int64
+
+	for {
+		var event models.OutboxEvent
+
+		filter := bson.D{
+			{"event_id", bson.D{{"$gt", after}}},
+			{"next_attempt_at", bson.D{{"$lte", now}}},
+			{"dead_lettered_at", bson.D{{"$exists", false}}},
+			{"locked_until", bson.D{{"$not", bson.D{{"$gt", now}}}}},
+		}
+
+		update := bson.D{
+			{"$set", bson.D{
+				{"owner", owner},
+				{"locked_until", now.Add(lease)},
+			},
+			},
+		}
+
+		err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.OutboxEvent{}, false, nil
+		}
+		if err != nil {
+			log.Error("failed to claim outbox event", sl.Err(err))
+			return models.OutboxEvent{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		filter = bson.D{
+			{"user_id", event.UserID},
+			{"family_id", event.FamilyID},
+			{"event_id", bson.D{{"$lt", event.ID}}},
+			{"dead_lettered_at", bson.D{{"$exists", false}}},
+		}
+
+		err = coll.FindOne(ctx, filter).Err()
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return event, true, nil
+		}
+		if err != nil {
+			log.Error("failed to search in db", sl.Err(err))
+			return models.OutboxEvent{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err = m.releaseOutboxEvent(ctx, coll, event.ID, owner, bson.D{}); err != nil {
+			log.Error("failed to release outbox event", sl.Err(err))
+			return models.OutboxEvent{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		after = event.ID
+	}
+}
+
+// RescheduleOutboxEvent stores the number of failed delivery attempts of the event and the time of the next one
+// and releases its lease. Nothing is changed if the event is not leased to owner anymore.
+func (m *MongoRepository) RescheduleOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	next time.Time,
+) error {
+	const op = "outbox.mongo.RescheduleOutboxEvent"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.OutboxCollection])
+
+	set := bson.D{
+		{"attempts", attempts},
+		{"next_attempt_at", next},
+	}
+
+	if err := m.releaseOutboxEvent(ctx, coll, eventID, owner, set); err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to update outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeadLetterOutboxEvent stops the delivery of the event leased to owner and keeps it with the error
+// of its last attempt. Later events of the same user and family are not held back by it.
+func (m *MongoRepository) DeadLetterOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	reason string,
+) error {
+	const op = "outbox.mongo.DeadLetterOutboxEvent"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.OutboxCollection])
+
+	set := bson.D{
+		{"attempts", attempts},
+		{"dead_lettered_at", time.Now().UTC()},
+		{"last_error", reason},
+	}
+
+	if err := m.releaseOutboxEvent(ctx, coll, eventID, owner, set); err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to update outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// releaseOutboxEvent removes the lease of the event if it is held by owner, setting the provided fields.
+func (m *MongoRepository) releaseOutboxEvent(
+	ctx context.Context,
+	coll *mongo.Collection,
+	eventID int64,
+	owner string,
+	set bson.D,
+) error {
+	filter := bson.D{
+		{"event_id", eventID},
+		{"owner", owner},
+	}
+
+	update := bson.D{
+		{"$unset", bson.D{
+			{"owner", ""},
+			{"locked_until", ""},
+		},
+		},
+	}
+	if len(set) > 0 {
+		update = append(update, bson.E{"$set", set})
+	}
+
+	_, err := coll.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// DeleteOutboxEvent removes a delivered event from the outbox collection.
+func (m *MongoRepository) DeleteOutboxEvent(ctx context.Context, eventID int64) error {
+	const op = "outbox.mongo.DeleteOutboxEvent"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.OutboxCollection])
+
+	filter := bson.D{
+		{"event_id", eventID},
+	}
+
+	_, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to delete outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"time"
+)
+
+// RevokeToken stores the revocation of the token with the given jti until the token expires.
+// Revocations are upserted by the unique jti index, so revoking a token twice keeps one document.
+func (m *MongoRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const op = "revocation.mongo.RevokeToken"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.RevocationCollection])
+
+	filter := bson.D{
+		{"jti", jti},
+	}
+
+	update := bson.D{
+		{"$set", bson.D{
+			{"expires_at", expiresAt},
+		},
+		},
+	}
+
+	_, err := coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to revoke token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeUserTokens stores the revocation of all tokens of the user issued before issuedBefore.
+// An earlier moment never overrides a later one. The document of the user is upserted by the unique user_id index.
+func (m *MongoRepository) RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time) error {
+	const op = "revocation.mongo.RevokeUserTokens"
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.RevocationCollection])
+
+	filter := bson.D{
+		{"user_id", userID},
+	}
+
+	update := bson.D{
+		{"$max", bson.D{
+			{"revoked_before", issuedBefore},
+		},
+		},
+	}
+
+	_, err := coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		m.log.With(slog.String("op", op)).Error("failed to revoke user's tokens", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks whether the token with the given jti, owner and issue time is revoked.
+// A zero issuedAt is treated as unknown, so the token is revoked if its owner has any revocation.
+// Otherwise the token is revoked if it was issued strictly before the revocation moment of its owner.
+func (m *MongoRepository) IsTokenRevoked(
+	ctx context.Context,
+	jti string,
+	userID int64,
+	issuedAt time.Time,
+) (bool, error) {
+	const op = "revocation.mongo.IsTokenRevoked"
+
+	var user models.RevokedUser
+
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	coll := m.Db.Database(m.Config.DBName).Collection(
+		m.Config.Collections[config.RevocationCollection])
+
+	if jti != "" {
+		filter := bson.D{
+			{"jti", jti},
+			{"expires_at", bson.D{{"$gt", time.Now()}}},
+		}
+
+		count, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			log.Error("failed to search in db", sl.Err(err))
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	filter := bson.D{
+		{"user_id", userID},
+	}
+
+	err := coll.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return issuedAt.IsZero() || issuedAt.Before(user.RevokedBefore), nil
+}
@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sequenceGenerator issues IDs from a counter document per collection in the sequence collection.
+// A missing counter document is created by the first request, so a fresh database needs no seeding.
+type sequenceGenerator struct {
+	repo *MongoRepository
+}
+
+// NextID increments the counter of the collection and returns its new value.
+// Concurrent upserts of a missing counter may conflict on the unique collection_name index,
+// in which case the increment is retried once against the counter created by the other request.
+func (g *sequenceGenerator) NextID(ctx context.Context, collectionName string) (int64, error) {
+	const op = "mongo.sequence.NextID"
+
+	id, err := g.increment(ctx, collectionName)
+	if mongo.IsDuplicateKeyError(err) {
+		id, err = g.increment(ctx, collectionName)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (g *sequenceGenerator) increment(ctx context.Context, collectionName string) (int64, error) {
+	var seq models.Sequence
+
+	coll := g.repo.Db.Database(g.repo.Config.DBName).Collection(
+		g.repo.Config.Collections[config.SequenceCollection])
+
+	filter := bson.D{
+		{"collection_name", collectionName},
+	}
+
+	update := bson.D{
+		{"$inc", bson.D{
+			{"counter", 1},
+		},
+		},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	res := coll.FindOneAndUpdate(ctx, filter, update, opts)
+	if res.Err() != nil {
+		return -1, fmt.Errorf("failed to get id: %w", res.Err())
+	}
+
+	if err := res.Decode(&seq); err != nil {
+		return -1, fmt.Errorf("failed to decode sequence: %w", err)
+	}
+
+	return seq.Counter, nil
+}
@@ -0,0 +1,31 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WithinTransaction runs fn inside a multi-document transaction. Repository calls
+// made with the context passed to fn are committed together if fn returns nil and
+// aborted otherwise. Calls made with a context which already carries a session join
+// its transaction.
+func (m *MongoRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "mongo.WithinTransaction"
+
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	sess, err := m.Db.StartSession()
+	if err != nil {
+		return fmt.Errorf("%s: failed to start session: %w", op, err)
+	}
+	defer sess.EndSession(ctx)
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	return err
+}
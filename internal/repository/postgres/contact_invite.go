@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+// RegisterContactInvite stores a pending invite to the family addressed to the contact and returns its ID.
+// An expired invite of the contact to the family is deleted first. Other duplicate invites are rejected
+// by the unique (contact, family_id) constraint, in which case ErrInviteExist is returned.
+func (r *PostgresRepository) RegisterContactInvite(
+	ctx context.Context,
+	familyID int64,
+	contact string,
+	inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
+	const op = "invite.postgres.RegisterContactInvite"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var id int64
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			"DELETE FROM contact_invites WHERE contact = $1 AND family_id = $2 AND expires_at <= now()",
+			contact, familyID)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, `
+			INSERT INTO contact_invites (family_id, contact, inviter_id, expires_at)
+			VALUES ($1, $2, $3, $4) RETURNING contact_invite_id`,
+			familyID, contact, inviterID, expiresAt).Scan(&id)
+	})
+	if isViolation(err, uniqueViolation) {
+		log.Warn(grpcerror.ErrInviteExist.Error())
+		return -1, grpcerror.ErrInviteExist
+	}
+	if isViolation(err, foreignKeyViolation) {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return -1, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+	if err != nil {
+		log.Error("failed to insert contact invite into db", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// TakeContactInvites removes and returns the unexpired pending invites addressed to any of the contacts.
+// Run in a transaction, the invites are removed only if it is committed.
+func (r *PostgresRepository) TakeContactInvites(
+	ctx context.Context,
+	contacts []string,
+) ([]models.ContactInvite, error) {
+	const op = "invite.postgres.TakeContactInvites"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, `
+		DELETE FROM contact_invites
+		WHERE contact = ANY($1) AND expires_at > now()
+		RETURNING contact_invite_id, family_id, contact, inviter_id, created_at, expires_at`,
+		contacts)
+	if err != nil {
+		log.Error("failed to delete contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invites, err := pgx.CollectRows(rows, scanContactInvite)
+	if err != nil {
+		log.Error("failed to decode contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// GetFamilyContactInvites retrieves the unexpired pending invites sent to a specific family ordered by their IDs.
+func (r *PostgresRepository) GetFamilyContactInvites(
+	ctx context.Context,
+	familyID int64,
+) ([]models.ContactInvite, error) {
+	const op = "invite.postgres.GetFamilyContactInvites"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT contact_invite_id, family_id, contact, inviter_id, created_at, expires_at
+		FROM contact_invites
+		WHERE family_id = $1 AND expires_at > now()
+		ORDER BY contact_invite_id`,
+		familyID)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invites, err := pgx.CollectRows(rows, scanContactInvite)
+	if err != nil {
+		log.Error("failed to decode contact invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// scanContactInvite scans a row of the contact_invites table with all its columns in the order they are declared.
+func scanContactInvite(row pgx.CollectableRow) (models.ContactInvite, error) {
+	var invite models.ContactInvite
+
+	err := row.Scan(&invite.ID, &invite.FamilyID, &invite.Contact, &invite.InviterID,
+		&invite.CreatedAt, &invite.ExpiresAt)
+
+	return invite, err
+}
+
+// RevokeContactInvite deletes the pending invite with the provided inviteID sent to the family.
+// If the family has no such pending invite, it returns ErrInviteNotFound.
+func (r *PostgresRepository) RevokeContactInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.postgres.RevokeContactInvite"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	tag, err := r.conn(ctx).Exec(ctx,
+		"DELETE FROM contact_invites WHERE contact_invite_id = $1 AND family_id = $2",
+		inviteID, familyID)
+	if err != nil {
+		log.Error("failed to delete contact invite", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn(grpcerror.ErrInviteNotFound.Error(),
+			slog.Int64("family_id", familyID),
+			slog.Int64("pending_invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	return nil
+}
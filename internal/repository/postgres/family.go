@@ -0,0 +1,689 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CreateFamily creates a new family in the database with the specified leader ID and profile.
+// The leader becomes the first member of the family with the owner role.
+func (r *PostgresRepository) CreateFamily(
+	ctx context.Context,
+	leaderID int64,
+	profile models.FamilyProfile,
+) (int64, error) {
+	const op = "family.postgres.CreateFamily"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var familyID int64
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO families (leader_id, name, description, avatar_url, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, now(), now())
+			RETURNING family_id`,
+			leaderID, profile.Name, profile.Description, profile.AvatarURL).Scan(&familyID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			"INSERT INTO family_members (family_id, user_id, role) VALUES ($1, $2, $3)",
+			familyID, leaderID, models.OwnerRole)
+		return err
+	})
+	if err != nil {
+		log.Error("failed to insert family into db", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return familyID, nil
+}
+
+// GetFamilyMembersID retrieves the member IDs of the family with the specified ID
+// in the order they joined the family.
+func (r *PostgresRepository) GetFamilyMembersID(ctx context.Context, familyID int64) ([]int64, error) {
+	const op = "family.postgres.GetFamilyMembersID"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx,
+		"SELECT user_id FROM family_members WHERE family_id = $1 ORDER BY position",
+		familyID)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	members, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		log.Error("failed to scan members", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// every family has at least one member, so an empty list means there is no such family
+	if len(members) == 0 {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return nil, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return members, nil
+}
+
+// GetFamilyLeaderID retrieves the leader's user ID of the family with the specified ID from the database.
+func (r *PostgresRepository) GetFamilyLeaderID(ctx context.Context, familyID int64) (int64, error) {
+	const op = "family.postgres.GetFamilyLeaderID"
+
+	var leaderID int64
+
+	err := r.conn(ctx).QueryRow(ctx,
+		"SELECT leader_id FROM families WHERE family_id = $1",
+		familyID).Scan(&leaderID)
+	if err != nil {
+		return -1, r.familyError(op, err)
+	}
+
+	return leaderID, nil
+}
+
+// IsUserInFamily checks whether the specified user is a member of the family with the given ID.
+func (r *PostgresRepository) IsUserInFamily(ctx context.Context, familyID, userID int64) (bool, error) {
+	const op = "family.postgres.IsUserInFamily"
+
+	var inFamily bool
+
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM family_members WHERE family_id = f.family_id AND user_id = $2)
+		FROM families f
+		WHERE f.family_id = $1`,
+		familyID, userID).Scan(&inFamily)
+	if err != nil {
+		return false, r.familyError(op, err)
+	}
+
+	return inFamily, nil
+}
+
+// AddUserToFamily adds a user to the specified family.
+// Duplicate membership is rejected by the primary key of family_members,
+// in which case ErrUserInFamily is returned.
+func (r *PostgresRepository) AddUserToFamily(ctx context.Context, familyID, userID int64) error {
+	const op = "family.postgres.AddUserToFamily"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	_, err := r.conn(ctx).Exec(ctx,
+		"INSERT INTO family_members (family_id, user_id) VALUES ($1, $2)",
+		familyID, userID)
+	if isViolation(err, uniqueViolation) {
+		log.Warn(grpcerror.ErrUserInFamily.Error())
+		return grpcerror.ErrUserInFamily
+	}
+	if isViolation(err, foreignKeyViolation) {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+	if err != nil {
+		log.Error("failed to insert family member into db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveUserFromFamily removes a user from the specified family.
+// If the user is the only member of the family, it deletes the entire family.
+// If the user being removed is the leader of the family, it passes the leadership and the owner role
+// to the next available member.
+func (r *PostgresRepository) RemoveUserFromFamily(ctx context.Context, familyID, userID int64) error {
+	const op = "family.postgres.RemoveUserFromFamily"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var leaderID int64
+
+		err := tx.QueryRow(ctx,
+			"SELECT leader_id FROM families WHERE family_id = $1 FOR UPDATE",
+			familyID).Scan(&leaderID)
+		if err != nil {
+			return r.familyError(op, err)
+		}
+
+		_, err = tx.Exec(ctx,
+			"DELETE FROM family_members WHERE family_id = $1 AND user_id = $2",
+			familyID, userID)
+		if err != nil {
+			return err
+		}
+
+		var nextMember int64
+
+		err = tx.QueryRow(ctx,
+			"SELECT user_id FROM family_members WHERE family_id = $1 ORDER BY position LIMIT 1",
+			familyID).Scan(&nextMember)
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, err = tx.Exec(ctx, "DELETE FROM families WHERE family_id = $1", familyID)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if leaderID != userID {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE families SET leader_id = $2 WHERE family_id = $1",
+			familyID, nextMember)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE family_members SET role = $3 WHERE family_id = $1 AND user_id = $2",
+			familyID, nextMember, models.OwnerRole)
+		return err
+	})
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return err
+	}
+	if err != nil {
+		log.Error("failed to remove user from family", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteFamily deletes the family with the specified ID from the database together
+// with its members and invites, and returns the IDs of its members.
+// If the family is not found, it returns ErrFamilyNotFound.
+func (r *PostgresRepository) DeleteFamily(ctx context.Context, familyID int64) ([]int64, error) {
+	const op = "family.postgres.DeleteFamily"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var members []int64
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			DELETE FROM family_members WHERE family_id = $1
+			RETURNING user_id`,
+			familyID)
+		if err != nil {
+			return err
+		}
+
+		members, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, "DELETE FROM families WHERE family_id = $1", familyID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			log.Warn(grpcerror.ErrFamilyNotFound.Error())
+			return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+		}
+
+		return nil
+	})
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("failed to delete family", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// familyColumns selects a family with its members in the order they joined the family.
+// Queries using it must group by f.family_id.
+const familyColumns = `
+	SELECT f.family_id, f.leader_id, f.succession_policy,
+	       f.name, f.description, f.avatar_url, f.created_at, f.updated_at,
+	       array_agg(m.user_id ORDER BY m.position), array_agg(m.role ORDER BY m.position),
+	       array_agg(m.joined_at ORDER BY m.position)
+	FROM families f
+	JOIN family_members m ON m.family_id = f.family_id`
+
+// ListFamilies retrieves up to limit families with IDs greater than afterFamilyID ordered by their IDs.
+func (r *PostgresRepository) ListFamilies(ctx context.Context, afterFamilyID int64, limit int) ([]models.Family, error) {
+	const op = "family.postgres.ListFamilies"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, familyColumns+`
+		WHERE f.family_id IN (
+			SELECT family_id FROM families
+			WHERE family_id > $1
+			ORDER BY family_id
+			LIMIT $2
+		)
+		GROUP BY f.family_id
+		ORDER BY f.family_id`,
+		afterFamilyID, limit)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	families, err := pgx.CollectRows(rows, scanFamily)
+	if err != nil {
+		log.Error("failed to scan families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// ListUserFamilies retrieves up to limit families of the user with IDs greater than afterFamilyID
+// ordered by their IDs. The families are found by the (user_id, family_id) index of family_members.
+func (r *PostgresRepository) ListUserFamilies(
+	ctx context.Context,
+	userID, afterFamilyID int64,
+	limit int,
+) ([]models.Family, error) {
+	const op = "family.postgres.ListUserFamilies"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, familyColumns+`
+		WHERE f.family_id IN (
+			SELECT family_id FROM family_members
+			WHERE user_id = $1 AND family_id > $2
+			ORDER BY family_id
+			LIMIT $3
+		)
+		GROUP BY f.family_id
+		ORDER BY f.family_id`,
+		userID, afterFamilyID, limit)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	families, err := pgx.CollectRows(rows, scanFamily)
+	if err != nil {
+		log.Error("failed to scan families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// familySortKeys maps the fields families are sorted by to their SQL expressions in familyColumns queries.
+// Families created before the creation time was recorded sort as created at the zero time.
+var familySortKeys = map[models.FamilySort]string{
+	models.SortByID:          "f.family_id",
+	models.SortByCreatedAt:   "COALESCE(f.created_at, '0001-01-01 00:00:00+00')",
+	models.SortByMemberCount: "count(*)",
+}
+
+// SearchFamilies retrieves up to limit families passing the filter, sorted as the filter requests,
+// which follow the position after in that order.
+func (r *PostgresRepository) SearchFamilies(
+	ctx context.Context,
+	filter models.FamilyFilter,
+	after *cursor.Position,
+	limit int,
+) ([]models.Family, error) {
+	const op = "family.postgres.SearchFamilies"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var (
+		where  = []string{"TRUE"}
+		having = []string{"TRUE"}
+		args   []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	key := familySortKeys[filter.SortBy]
+	createdAt := familySortKeys[models.SortByCreatedAt]
+
+	if filter.LeaderID != 0 {
+		where = append(where, "f.leader_id = "+arg(filter.LeaderID))
+	}
+	if filter.MemberID != 0 {
+		where = append(where,
+			"f.family_id IN (SELECT family_id FROM family_members WHERE user_id = "+arg(filter.MemberID)+")")
+	}
+	if !filter.CreatedAfter.IsZero() {
+		where = append(where, createdAt+" >= "+arg(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		where = append(where, createdAt+" < "+arg(filter.CreatedBefore))
+	}
+	if filter.MinMembers > 0 {
+		having = append(having, "count(*) >= "+arg(filter.MinMembers))
+	}
+	if filter.MaxMembers > 0 {
+		having = append(having, "count(*) <= "+arg(filter.MaxMembers))
+	}
+
+	next, order := ">", "ASC"
+	if filter.Descending {
+		next, order = "<", "DESC"
+	}
+
+	if after != nil {
+		var afterKey any = after.Key
+		if filter.SortBy == models.SortByCreatedAt {
+			afterKey = time.UnixMicro(after.Key)
+		}
+
+		having = append(having, fmt.Sprintf("(%s, f.family_id) %s (%s, %s)",
+			key, next, arg(afterKey), arg(after.ID)))
+	}
+
+	query := familyColumns + `
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY f.family_id
+		HAVING ` + strings.Join(having, " AND ") + `
+		ORDER BY ` + key + ` ` + order + `, f.family_id ` + order + `
+		LIMIT ` + arg(limit)
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	families, err := pgx.CollectRows(rows, scanFamily)
+	if err != nil {
+		log.Error("failed to scan families", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return families, nil
+}
+
+// GetFamily retrieves the family with the specified ID from the database.
+func (r *PostgresRepository) GetFamily(ctx context.Context, familyID int64) (models.Family, error) {
+	const op = "family.postgres.GetFamily"
+
+	rows, err := r.conn(ctx).Query(ctx, familyColumns+`
+		WHERE f.family_id = $1
+		GROUP BY f.family_id`,
+		familyID)
+	if err != nil {
+		return models.Family{}, r.familyError(op, err)
+	}
+
+	family, err := pgx.CollectExactlyOneRow(rows, scanFamily)
+	if err != nil {
+		return models.Family{}, r.familyError(op, err)
+	}
+
+	return family, nil
+}
+
+// TransferLeadership makes the member toUserID the leader and owner of the family instead of fromUserID,
+// who becomes a co-leader. If fromUserID is no longer the leader, it returns ErrNotLeader;
+// if toUserID is not a member of the family, it returns ErrUserNotInFamily.
+func (r *PostgresRepository) TransferLeadership(ctx context.Context, familyID, fromUserID, toUserID int64) error {
+	const op = "family.postgres.TransferLeadership"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var leaderID int64
+
+		err := tx.QueryRow(ctx,
+			"SELECT leader_id FROM families WHERE family_id = $1 FOR UPDATE",
+			familyID).Scan(&leaderID)
+		if err != nil {
+			return r.familyError(op, err)
+		}
+
+		if leaderID != fromUserID {
+			log.Warn(grpcerror.ErrNotLeader.Error())
+			return fmt.Errorf("%s: %w", op, grpcerror.ErrNotLeader)
+		}
+
+		tag, err := tx.Exec(ctx,
+			"UPDATE family_members SET role = $3 WHERE family_id = $1 AND user_id = $2",
+			familyID, toUserID, models.OwnerRole)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			log.Warn(grpcerror.ErrUserNotInFamily.Error())
+			return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE family_members SET role = $3 WHERE family_id = $1 AND user_id = $2",
+			familyID, fromUserID, models.CoLeaderRole)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE families SET leader_id = $2 WHERE family_id = $1",
+			familyID, toUserID)
+		return err
+	})
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) ||
+		errors.Is(err, grpcerror.ErrNotLeader) ||
+		errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return err
+	}
+	if err != nil {
+		log.Error("failed to transfer leadership", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetSuccessionPolicy changes the succession policy of the specified family.
+func (r *PostgresRepository) SetSuccessionPolicy(
+	ctx context.Context,
+	familyID int64,
+	policy models.SuccessionPolicy,
+) error {
+	const op = "family.postgres.SetSuccessionPolicy"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE families SET succession_policy = $2 WHERE family_id = $1",
+		familyID, policy)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return nil
+}
+
+// UpdateFamily changes the profile fields set in the update and the update time of the family.
+func (r *PostgresRepository) UpdateFamily(ctx context.Context, familyID int64, update models.FamilyUpdate) error {
+	const op = "family.postgres.UpdateFamily"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	// nil fields are passed as NULL and keep the current values
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE families
+		SET name        = COALESCE($2, name),
+		    description = COALESCE($3, description),
+		    avatar_url  = COALESCE($4, avatar_url),
+		    updated_at  = now()
+		WHERE family_id = $1`,
+		familyID, update.Name, update.Description, update.AvatarURL)
+	if err != nil {
+		log.Error("failed to update family in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	return nil
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
+
+// scanFamily scans a row selected with familyColumns.
+func scanFamily(row pgx.CollectableRow) (models.Family, error) {
+	var (
+		family               models.Family
+		policy               string
+		createdAt, updatedAt *time.Time
+		roles                []string
+		joinedAt             []*time.Time
+	)
+
+	err := row.Scan(&family.ID, &family.LeaderUserID, &policy,
+		&family.Name, &family.Description, &family.AvatarURL, &createdAt, &updatedAt,
+		&family.MembersID, &roles, &joinedAt)
+	if err != nil {
+		return family, err
+	}
+
+	family.SuccessionPolicy = models.SuccessionPolicy(policy)
+	family.CreatedAt = timeOrZero(createdAt)
+	family.UpdatedAt = timeOrZero(updatedAt)
+	family.MemberCount = len(family.MembersID)
+
+	family.Members = make([]models.Member, 0, len(roles))
+	for i, role := range roles {
+		family.Members = append(family.Members, models.Member{
+			UserID:   family.MembersID[i],
+			Role:     models.FamilyRole(role),
+			JoinedAt: timeOrZero(joinedAt[i]),
+		})
+	}
+
+	return family, nil
+}
+
+// GetMemberRole retrieves the role of the user in the specified family.
+// If the user is not a member of the family, it returns ErrUserNotInFamily.
+func (r *PostgresRepository) GetMemberRole(ctx context.Context, familyID, userID int64) (models.FamilyRole, error) {
+	const op = "family.postgres.GetMemberRole"
+
+	var role *string
+
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT m.role
+		FROM families f
+		LEFT JOIN family_members m ON m.family_id = f.family_id AND m.user_id = $2
+		WHERE f.family_id = $1`,
+		familyID, userID).Scan(&role)
+	if err != nil {
+		return "", r.familyError(op, err)
+	}
+
+	if role == nil {
+		return "", fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return models.FamilyRole(*role), nil
+}
+
+// SetMemberRole changes the role of the user in the specified family.
+// If nothing was updated, it checks whether the family exists to return either
+// ErrFamilyNotFound or ErrUserNotInFamily.
+func (r *PostgresRepository) SetMemberRole(
+	ctx context.Context,
+	familyID, userID int64,
+	role models.FamilyRole,
+) error {
+	const op = "family.postgres.SetMemberRole"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE family_members SET role = $3 WHERE family_id = $1 AND user_id = $2",
+		familyID, userID, role)
+	if err != nil {
+		log.Error("failed to update family member in db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		if _, err = r.GetFamilyLeaderID(ctx, familyID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Warn(grpcerror.ErrUserNotInFamily.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
+	}
+
+	return nil
+}
+
+// familyError converts pgx.ErrNoRows into ErrFamilyNotFound and wraps any other error with op.
+func (r *PostgresRepository) familyError(op string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.log.With(slog.String("op", op)).Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+
+	r.log.With(slog.String("op", op)).Error("failed to search in db", sl.Err(err))
+
+	return fmt.Errorf("%s: %w", op, err)
+}
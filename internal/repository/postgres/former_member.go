@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+// AddFormerMembers records that the users are not members of the family anymore.
+// A user who left the family before has the time of leaving updated.
+func (r *PostgresRepository) AddFormerMembers(ctx context.Context, familyID int64, userIDs ...int64) error {
+	const op = "formermember.postgres.AddFormerMembers"
+
+	_, err := r.conn(ctx).Exec(ctx, `
+		INSERT INTO former_members (user_id, family_id, left_at)
+		SELECT user_id, $1, now() FROM unnest($2::BIGINT[]) AS user_id
+		ON CONFLICT (user_id, family_id) DO UPDATE SET left_at = EXCLUDED.left_at`,
+		familyID, userIDs)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to store former members", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetFormerMembers retrieves all recorded former members of families.
+func (r *PostgresRepository) GetFormerMembers(ctx context.Context) ([]models.FormerMember, error) {
+	const op = "formermember.postgres.GetFormerMembers"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, "SELECT user_id, family_id, left_at FROM former_members")
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	members, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FormerMember, error) {
+		var member models.FormerMember
+		err := row.Scan(&member.UserID, &member.FamilyID, &member.LeftAt)
+		return member, err
+	})
+	if err != nil {
+		log.Error("failed to scan former members", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// DeleteFormerMembers forgets the families the user left before leftBefore.
+func (r *PostgresRepository) DeleteFormerMembers(ctx context.Context, userID int64, leftBefore time.Time) error {
+	const op = "formermember.postgres.DeleteFormerMembers"
+
+	_, err := r.conn(ctx).Exec(ctx,
+		"DELETE FROM former_members WHERE user_id = $1 AND left_at < $2",
+		userID, leftBefore)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to delete former members", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+// notExpired matches invites which have not expired yet, including invites without expires_at,
+// which never expire.
+const notExpired = "(expires_at IS NULL OR expires_at > now())"
+
+// RegisterInvite registers a new invite sent by inviterID expiring at expiresAt in the database and returns its ID.
+// An expired invite of the user to the family is deleted first. Other duplicate invites are rejected
+// by the unique (family_id, user_id) constraint, in which case ErrInviteExist is returned.
+func (r *PostgresRepository) RegisterInvite(
+	ctx context.Context,
+	familyID, userID, inviterID int64,
+	expiresAt time.Time,
+) (int64, error) {
+	const op = "invite.postgres.RegisterInvite"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var id int64
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			"DELETE FROM invites WHERE family_id = $1 AND user_id = $2 AND expires_at <= now()",
+			familyID, userID)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx,
+			`INSERT INTO invites (family_id, user_id, inviter_id, expires_at)
+			VALUES ($1, $2, $3, $4) RETURNING invite_id`,
+			familyID, userID, inviterID, expiresAt).Scan(&id)
+	})
+	if isViolation(err, uniqueViolation) {
+		log.Warn(grpcerror.ErrInviteExist.Error())
+		return -1, grpcerror.ErrInviteExist
+	}
+	if isViolation(err, foreignKeyViolation) {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return -1, fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+	if err != nil {
+		log.Error("failed to insert new invite into db", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// inviteColumns selects an invite. Invites sent before inviters were recorded have inviter_id 0.
+const inviteColumns = `
+	SELECT invite_id, family_id, user_id, COALESCE(inviter_id, 0), created_at, expires_at
+	FROM invites`
+
+// GetInvites retrieves unexpired invites for a specific user from the database.
+func (r *PostgresRepository) GetInvites(ctx context.Context, userID int64) ([]models.Invite, error) {
+	const op = "invite.postgres.GetInvites"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, inviteColumns+`
+		WHERE user_id = $1 AND `+notExpired+`
+		ORDER BY invite_id`,
+		userID)
+	if err != nil {
+		log.Error("failed to search in db:", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invites, err := pgx.CollectRows(rows, scanInvite)
+	if err != nil {
+		log.Error("failed to decode invites:", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// GetFamilyInvites retrieves unexpired invites to a specific family from the database ordered by their IDs.
+func (r *PostgresRepository) GetFamilyInvites(ctx context.Context, familyID int64) ([]models.Invite, error) {
+	const op = "invite.postgres.GetFamilyInvites"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, inviteColumns+`
+		WHERE family_id = $1 AND `+notExpired+`
+		ORDER BY invite_id`,
+		familyID)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invites, err := pgx.CollectRows(rows, scanInvite)
+	if err != nil {
+		log.Error("failed to decode invites", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// scanInvite scans a row selected with inviteColumns.
+func scanInvite(row pgx.CollectableRow) (models.Invite, error) {
+	var (
+		invite               models.Invite
+		createdAt, expiresAt *time.Time
+	)
+
+	err := row.Scan(&invite.ID, &invite.FamilyID, &invite.UserID, &invite.InviterID, &createdAt, &expiresAt)
+	invite.CreatedAt = timeOrZero(createdAt)
+	invite.ExpiresAt = timeOrZero(expiresAt)
+
+	return invite, err
+}
+
+// IsUserInvited checks if a user with a specific ID has an unexpired invite to join a family with a specific ID.
+func (r *PostgresRepository) IsUserInvited(ctx context.Context, familyID, userID int64) (bool, error) {
+	const op = "invite.postgres.IsUserInvited"
+
+	var invited bool
+
+	err := r.conn(ctx).QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM invites WHERE family_id = $1 AND user_id = $2 AND "+notExpired+")",
+		familyID, userID).Scan(&invited)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to search in db", sl.Err(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invited, nil
+}
+
+// AcceptInvite removes the invite with the provided inviteID addressed to userID
+// and returns the ID of the family associated with the invite.
+// An expired invite is left for the sweeper and ErrInviteExpired is returned.
+func (r *PostgresRepository) AcceptInvite(ctx context.Context, userID, inviteID int64) (int64, error) {
+	const op = "invite.postgres.AcceptInvite"
+
+	var familyID int64
+
+	err := r.conn(ctx).QueryRow(ctx,
+		"DELETE FROM invites WHERE invite_id = $1 AND user_id = $2 AND "+notExpired+" RETURNING family_id",
+		inviteID, userID).Scan(&familyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var expired bool
+
+		err = r.conn(ctx).QueryRow(ctx,
+			"SELECT EXISTS (SELECT 1 FROM invites WHERE invite_id = $1 AND user_id = $2)",
+			inviteID, userID).Scan(&expired)
+		if err == nil && expired {
+			r.log.With(slog.String("op", op)).
+				Warn(grpcerror.ErrInviteExpired.Error(), slog.Int64("invite_id", inviteID))
+			return -1, grpcerror.ErrInviteExpired
+		}
+		if err == nil {
+			err = pgx.ErrNoRows
+		}
+	}
+	if err != nil {
+		return -1, r.inviteError(op, err, userID, inviteID)
+	}
+
+	return familyID, nil
+}
+
+// DenyInvite removes the invite with the provided inviteID addressed to userID.
+func (r *PostgresRepository) DenyInvite(ctx context.Context, userID, inviteID int64) error {
+	const op = "invite.postgres.DenyInvite"
+
+	var familyID int64
+
+	err := r.conn(ctx).QueryRow(ctx,
+		"DELETE FROM invites WHERE invite_id = $1 AND user_id = $2 RETURNING family_id",
+		inviteID, userID).Scan(&familyID)
+	if err != nil {
+		return r.inviteError(op, err, userID, inviteID)
+	}
+
+	return nil
+}
+
+// RevokeInvite removes the invite with the provided inviteID sent to the family with the provided familyID,
+// whether it is expired or not. If there is no such invite, it returns ErrInviteNotFound.
+func (r *PostgresRepository) RevokeInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.postgres.RevokeInvite"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	tag, err := r.conn(ctx).Exec(ctx,
+		"DELETE FROM invites WHERE invite_id = $1 AND family_id = $2",
+		inviteID, familyID)
+	if err != nil {
+		log.Error("failed to delete invite", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn(grpcerror.ErrInviteNotFound.Error(),
+			slog.Int64("family_id", familyID),
+			slog.Int64("invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// DeleteUserInvites deletes all invites associated with a specific user.
+func (r *PostgresRepository) DeleteUserInvites(ctx context.Context, userID int64) error {
+	const op = "invite.postgres.DeleteUserInvites"
+
+	_, err := r.conn(ctx).Exec(ctx, "DELETE FROM invites WHERE user_id = $1", userID)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to delete user's invites", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredInvites deletes the invites, including pending invites addressed to contacts,
+// expired at the moment now and returns their number.
+func (r *PostgresRepository) DeleteExpiredInvites(ctx context.Context, now time.Time) (int64, error) {
+	const op = "invite.postgres.DeleteExpiredInvites"
+
+	var deleted int64
+
+	for _, table := range []string{"invites", "contact_invites"} {
+		tag, err := r.conn(ctx).Exec(ctx, "DELETE FROM "+table+" WHERE expires_at <= $1", now)
+		if err != nil {
+			r.log.With(slog.String("op", op)).Error("failed to delete expired invites", sl.Err(err))
+			return deleted, fmt.Errorf("%s: %w", op, err)
+		}
+
+		deleted += tag.RowsAffected()
+	}
+
+	return deleted, nil
+}
+
+// inviteError converts pgx.ErrNoRows into ErrInviteNotFound and wraps any other error with op.
+func (r *PostgresRepository) inviteError(op string, err error, userID, inviteID int64) error {
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn(grpcerror.ErrInviteNotFound.Error(),
+			slog.Int64("user_id", userID),
+			slog.Int64("invite_id", inviteID))
+		return grpcerror.ErrInviteNotFound
+	}
+
+	log.Error("failed to find and delete invite", sl.Err(err))
+
+	return fmt.Errorf("%s: %w", op, err)
+}
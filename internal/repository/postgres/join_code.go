@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+// activeJoinCode matches join codes which can be redeemed now.
+const activeJoinCode = "NOT disabled AND " + notExpired + " AND (max_uses = 0 OR uses < max_uses)"
+
+// CreateJoinCode stores a new join code. If the same code is already stored, it returns ErrJoinCodeExist.
+// A code of a family which does not exist is rejected with ErrFamilyNotFound.
+func (r *PostgresRepository) CreateJoinCode(ctx context.Context, code models.JoinCode) error {
+	const op = "joincode.postgres.CreateJoinCode"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var expiresAt *time.Time
+	if !code.ExpiresAt.IsZero() {
+		expiresAt = &code.ExpiresAt
+	}
+
+	_, err := r.conn(ctx).Exec(ctx, `
+		INSERT INTO join_codes (code, family_id, created_by, max_uses, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		code.Code, code.FamilyID, code.CreatedBy, code.MaxUses, code.CreatedAt, expiresAt)
+	if isViolation(err, uniqueViolation) {
+		log.Warn(grpcerror.ErrJoinCodeExist.Error())
+		return grpcerror.ErrJoinCodeExist
+	}
+	if isViolation(err, foreignKeyViolation) {
+		log.Warn(grpcerror.ErrFamilyNotFound.Error())
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrFamilyNotFound)
+	}
+	if err != nil {
+		log.Error("failed to insert join code into db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetFamilyJoinCodes retrieves the active join codes of a specific family ordered by their creation time.
+func (r *PostgresRepository) GetFamilyJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error) {
+	const op = "joincode.postgres.GetFamilyJoinCodes"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT code, family_id, created_by, max_uses, uses, created_at, expires_at, disabled
+		FROM join_codes
+		WHERE family_id = $1 AND `+activeJoinCode+`
+		ORDER BY created_at, code`,
+		familyID)
+	if err != nil {
+		log.Error("failed to search in db", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	codes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.JoinCode, error) {
+		var (
+			code      models.JoinCode
+			expiresAt *time.Time
+		)
+
+		err := row.Scan(&code.Code, &code.FamilyID, &code.CreatedBy, &code.MaxUses, &code.Uses,
+			&code.CreatedAt, &expiresAt, &code.Disabled)
+		code.ExpiresAt = timeOrZero(expiresAt)
+
+		return code, err
+	})
+	if err != nil {
+		log.Error("failed to decode join codes", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// DisableJoinCode disables the join code of a specific family, so it can no longer be redeemed.
+// If the family has no such code, it returns ErrJoinCodeNotFound.
+func (r *PostgresRepository) DisableJoinCode(ctx context.Context, familyID int64, code string) error {
+	const op = "joincode.postgres.DisableJoinCode"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE join_codes SET disabled = true WHERE code = $1 AND family_id = $2",
+		code, familyID)
+	if err != nil {
+		log.Error("failed to disable join code", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn(grpcerror.ErrJoinCodeNotFound.Error(), slog.Int64("family_id", familyID))
+		return grpcerror.ErrJoinCodeNotFound
+	}
+
+	return nil
+}
+
+// RedeemJoinCode counts a use of the active join code and returns the ID of its family.
+// The code is checked and its uses are incremented in one statement, so a code is never
+// redeemed more times than allowed. If there is no such active code, it returns ErrJoinCodeNotFound.
+func (r *PostgresRepository) RedeemJoinCode(ctx context.Context, code string) (int64, error) {
+	const op = "joincode.postgres.RedeemJoinCode"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	var familyID int64
+
+	err := r.conn(ctx).QueryRow(ctx,
+		"UPDATE join_codes SET uses = uses + 1 WHERE code = $1 AND "+activeJoinCode+" RETURNING family_id",
+		code).Scan(&familyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn(grpcerror.ErrJoinCodeNotFound.Error())
+		return -1, grpcerror.ErrJoinCodeNotFound
+	}
+	if err != nil {
+		log.Error("failed to redeem join code", sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return familyID, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationsLockID is the key of the advisory lock which prevents several
+// instances of the service from applying migrations simultaneously.
+const migrationsLockID = 7_355_608
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type migration struct {
+	version int64
+	name    string
+	query   string
+}
+
+// migrate applies all embedded migrations which have not been applied yet.
+// Every migration is executed in its own transaction together with the record
+// of its version in the schema_migrations table.
+func (r *PostgresRepository) migrate(ctx context.Context) error {
+	const op = "postgres.migrate"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	conn, err := r.Db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+		return fmt.Errorf("%s: failed to acquire lock: %w", op, err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID)
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    BIGINT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("%s: failed to create schema_migrations: %w", op, err)
+	}
+
+	var current int64
+
+	err = conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get schema version: %w", op, err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		log.Info("applying migration", slog.String("name", m.name))
+
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, m.query); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: failed to apply %s: %w", op, m.name, err)
+		}
+	}
+
+	log.Info("schema is up to date", slog.Int64("version", lastVersion(migrations, current)))
+
+	return nil
+}
+
+// loadMigrations reads embedded migrations sorted by version.
+// File names must have the form <version>_<description>.sql.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(entries))
+
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+
+		query, err := fs.ReadFile(migrationsFS, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    entry.Name(),
+			query:   string(query),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func lastVersion(migrations []migration, current int64) int64 {
+	if len(migrations) == 0 || migrations[len(migrations)-1].version < current {
+		return current
+	}
+
+	return migrations[len(migrations)-1].version
+}
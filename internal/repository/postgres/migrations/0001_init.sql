@@ -0,0 +1,30 @@
+CREATE TABLE families
+(
+    family_id BIGSERIAL PRIMARY KEY,
+    leader_id BIGINT NOT NULL
+);
+
+CREATE TABLE family_members
+(
+    family_id BIGINT    NOT NULL REFERENCES families (family_id) ON DELETE CASCADE,
+    user_id   BIGINT    NOT NULL,
+    position  BIGSERIAL NOT NULL,
+    PRIMARY KEY (family_id, user_id)
+);
+
+CREATE INDEX family_members_user_id_idx ON family_members (user_id);
+
+ALTER TABLE families
+    ADD CONSTRAINT families_leader_fk
+        FOREIGN KEY (family_id, leader_id) REFERENCES family_members (family_id, user_id)
+            DEFERRABLE INITIALLY DEFERRED;
+
+CREATE TABLE invites
+(
+    invite_id BIGSERIAL PRIMARY KEY,
+    family_id BIGINT NOT NULL REFERENCES families (family_id) ON DELETE CASCADE,
+    user_id   BIGINT NOT NULL,
+    UNIQUE (family_id, user_id)
+);
+
+CREATE INDEX invites_user_id_idx ON invites (user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE outbox
+(
+    event_id        BIGSERIAL PRIMARY KEY,
+    action          TEXT        NOT NULL,
+    user_id         BIGINT      NOT NULL,
+    family_id       BIGINT      NOT NULL,
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX outbox_next_attempt_at_idx ON outbox (next_attempt_at);
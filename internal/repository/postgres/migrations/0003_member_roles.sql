@@ -0,0 +1,9 @@
+ALTER TABLE family_members
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+        CHECK (role IN ('owner', 'co_leader', 'member', 'viewer'));
+
+UPDATE family_members m
+SET role = 'owner'
+FROM families f
+WHERE f.family_id = m.family_id
+  AND f.leader_id = m.user_id;
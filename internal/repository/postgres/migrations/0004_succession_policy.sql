@@ -0,0 +1,3 @@
+ALTER TABLE families
+    ADD COLUMN succession_policy TEXT NOT NULL DEFAULT 'longest_tenured'
+        CHECK (succession_policy IN ('longest_tenured', 'oldest_member', 'require_transfer'));
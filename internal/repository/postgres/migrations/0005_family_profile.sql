@@ -0,0 +1,13 @@
+ALTER TABLE families
+    ADD COLUMN name        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url  TEXT NOT NULL DEFAULT '',
+    -- unknown for families created before this migration
+    ADD COLUMN created_at  TIMESTAMPTZ,
+    ADD COLUMN updated_at  TIMESTAMPTZ;
+
+ALTER TABLE family_members
+    ADD COLUMN joined_at TIMESTAMPTZ;
+
+ALTER TABLE family_members
+    ALTER COLUMN joined_at SET DEFAULT now();
@@ -0,0 +1,3 @@
+CREATE INDEX family_members_user_id_family_id_idx ON family_members (user_id, family_id);
+
+DROP INDEX family_members_user_id_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX families_leader_id_family_id_idx ON families (leader_id, family_id);
+
+CREATE INDEX families_created_at_family_id_idx ON families (created_at, family_id);
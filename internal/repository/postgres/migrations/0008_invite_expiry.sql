@@ -0,0 +1,9 @@
+-- unknown for invites sent before this migration, which never expire
+ALTER TABLE invites
+    ADD COLUMN created_at TIMESTAMPTZ,
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+ALTER TABLE invites
+    ALTER COLUMN created_at SET DEFAULT now();
+
+CREATE INDEX invites_expires_at_idx ON invites (expires_at);
@@ -0,0 +1,3 @@
+-- unknown for invites sent before this migration
+ALTER TABLE invites
+    ADD COLUMN inviter_id BIGINT;
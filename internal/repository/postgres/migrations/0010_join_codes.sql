@@ -0,0 +1,13 @@
+CREATE TABLE join_codes
+(
+    code       TEXT PRIMARY KEY,
+    family_id  BIGINT      NOT NULL REFERENCES families (family_id) ON DELETE CASCADE,
+    created_by BIGINT      NOT NULL,
+    max_uses   INT         NOT NULL DEFAULT 0,
+    uses       INT         NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ,
+    disabled   BOOLEAN     NOT NULL DEFAULT false
+);
+
+CREATE INDEX join_codes_family_id_created_at_idx ON join_codes (family_id, created_at);
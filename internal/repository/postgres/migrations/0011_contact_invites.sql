@@ -0,0 +1,12 @@
+CREATE TABLE contact_invites
+(
+    contact_invite_id BIGSERIAL PRIMARY KEY,
+    family_id         BIGINT      NOT NULL REFERENCES families (family_id) ON DELETE CASCADE,
+    contact           TEXT        NOT NULL,
+    inviter_id        BIGINT      NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at        TIMESTAMPTZ NOT NULL,
+    UNIQUE (contact, family_id)
+);
+
+CREATE INDEX contact_invites_expires_at_idx ON contact_invites (expires_at);
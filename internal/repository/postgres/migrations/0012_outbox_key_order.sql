@@ -0,0 +1 @@
+CREATE INDEX outbox_user_id_family_id_event_id_idx ON outbox (user_id, family_id, event_id);
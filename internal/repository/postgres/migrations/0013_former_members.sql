@@ -0,0 +1,7 @@
+CREATE TABLE former_members
+(
+    user_id   BIGINT      NOT NULL,
+    family_id BIGINT      NOT NULL,
+    left_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, family_id)
+);
@@ -0,0 +1,8 @@
+ALTER TABLE outbox
+    ADD COLUMN owner            TEXT,
+    ADD COLUMN locked_until     TIMESTAMPTZ,
+    ADD COLUMN dead_lettered_at TIMESTAMPTZ,
+    ADD COLUMN last_error       TEXT;
+
+DROP INDEX outbox_next_attempt_at_idx;
+CREATE INDEX outbox_live_event_id_idx ON outbox (event_id) WHERE dead_lettered_at IS NULL;
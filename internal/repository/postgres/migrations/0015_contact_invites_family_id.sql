@@ -0,0 +1 @@
+CREATE INDEX contact_invites_family_id_idx ON contact_invites (family_id, contact_invite_id);
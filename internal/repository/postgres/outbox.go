@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"time"
+)
+
+// AddOutboxEvents inserts the provided events into the outbox table.
+func (r *PostgresRepository) AddOutboxEvents(ctx context.Context, events ...models.OutboxEvent) error {
+	const op = "outbox.postgres.AddOutboxEvents"
+
+	batch := &pgx.Batch{}
+
+	for _, event := range events {
+		batch.Queue(`
+			INSERT INTO outbox (action, user_id, family_id, attempts, next_attempt_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			event.Action, event.UserID, event.FamilyID,
+			event.Attempts, event.NextAttemptAt, event.CreatedAt)
+	}
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to insert outbox events into db", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimOutboxEvent leases the oldest event which is due for delivery at the moment now to owner
+// for the lease duration and returns it. Events of the same user and family are applied in the order
+// they were stored, so an event is not claimed while an earlier live event of the pair exists.
+// Rows locked by concurrent claims are skipped. The second result is false if there is no event to claim.
+func (r *PostgresRepository) ClaimOutboxEvent(
+	ctx context.Context,
+	owner string,
+	now time.Time,
+	lease time.Duration,
+) (models.OutboxEvent, bool, error) {
+	const op = "outbox.postgres.ClaimOutboxEvent"
+
+	var event models.OutboxEvent
+
+	err := r.conn(ctx).QueryRow(ctx, `
+		UPDATE outbox
+		SET owner = $1, locked_until = $3
+		WHERE event_id = (
+		    SELECT event_id
+		    FROM outbox o
+		    WHERE next_attempt_at <= $2
+		      AND dead_lettered_at IS NULL
+		      AND (locked_until IS NULL OR locked_until <= $2)
+		      AND NOT EXISTS (
+		          SELECT 1
+		          FROM outbox earlier
+		          WHERE earlier.user_id = o.user_id
+		            AND earlier.family_id = o.family_id
+		            AND earlier.event_id < o.event_id
+		            AND earlier.dead_lettered_at IS NULL)
+		    ORDER BY event_id
+		    LIMIT 1
+		    FOR UPDATE SKIP LOCKED)
+		RETURNING event_id, action, user_id, family_id, attempts, next_attempt_at, created_at, owner, locked_until`,
+		owner, now, now.Add(lease)).
+		Scan(&event.ID, &event.Action, &event.UserID, &event.FamilyID,
+			&event.Attempts, &event.NextAttemptAt, &event.CreatedAt, &event.Owner, &event.LockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.OutboxEvent{}, false, nil
+	}
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to claim outbox event", sl.Err(err))
+		return models.OutboxEvent{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return event, true, nil
+}
+
+// RescheduleOutboxEvent stores the number of failed delivery attempts of the event and the time of the next one
+// and releases its lease. Nothing is changed if the event is not leased to owner anymore.
+func (r *PostgresRepository) RescheduleOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	next time.Time,
+) error {
+	const op = "outbox.postgres.RescheduleOutboxEvent"
+
+	_, err := r.conn(ctx).Exec(ctx, `
+		UPDATE outbox
+		SET attempts = $3, next_attempt_at = $4, owner = NULL, locked_until = NULL
+		WHERE event_id = $1 AND owner = $2`,
+		eventID, owner, attempts, next)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to update outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeadLetterOutboxEvent stops the delivery of the event leased to owner and keeps it with the error
+// of its last attempt. Later events of the same user and family are not held back by it.
+func (r *PostgresRepository) DeadLetterOutboxEvent(
+	ctx context.Context,
+	eventID int64,
+	owner string,
+	attempts int,
+	reason string,
+) error {
+	const op = "outbox.postgres.DeadLetterOutboxEvent"
+
+	_, err := r.conn(ctx).Exec(ctx, `
+		UPDATE outbox
+		SET attempts = $3, dead_lettered_at = now(), last_error = $4, owner = NULL, locked_until = NULL
+		WHERE event_id = $1 AND owner = $2`,
+		eventID, owner, attempts, reason)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to update outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteOutboxEvent removes a delivered event from the outbox table.
+func (r *PostgresRepository) DeleteOutboxEvent(ctx context.Context, eventID int64) error {
+	const op = "outbox.postgres.DeleteOutboxEvent"
+
+	_, err := r.conn(ctx).Exec(ctx, "DELETE FROM outbox WHERE event_id = $1", eventID)
+	if err != nil {
+		r.log.With(slog.String("op", op)).Error("failed to delete outbox event", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+type PostgresRepository struct {
+	Db     *pgxpool.Pool
+	Config *config.PostgresConfig
+	log    *slog.Logger
+}
+
+// InitPostgresRepository initializes a new PostgresRepository instance with the provided
+// configuration and logger. It establishes a connection pool to the PostgreSQL server,
+// performs a ping to ensure connectivity, applies pending schema migrations and returns
+// the initialized PostgresRepository instance.
+func InitPostgresRepository(cfg *config.PostgresConfig, logger *slog.Logger) (
+	*PostgresRepository, error) {
+	const op = "postgres.InitPostgresRepository"
+
+	log := logger.With(
+		slog.String("op", op),
+	)
+
+	conn := fmt.Sprintf(cfg.ConnectionString, cfg.User, cfg.Password)
+
+	log.Info("trying to connect to postgres")
+
+	db, err := pgxpool.New(context.TODO(), conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	log.Info("connected successfully")
+	log.Info("trying to ping postgres")
+
+	if err = db.Ping(context.TODO()); err != nil {
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+	log.Info("pinged successfully")
+
+	repo := &PostgresRepository{
+		Db:     db,
+		Config: cfg,
+		log:    logger,
+	}
+
+	if err = repo.migrate(context.TODO()); err != nil {
+		return nil, fmt.Errorf("failed to migrate postgres: %w", err)
+	}
+
+	return repo, nil
+}
+
+type txKey struct{}
+
+// querier is the subset of methods shared by the connection pool and transactions.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// WithinTransaction runs fn inside a database transaction. Repository calls made with
+// the context passed to fn are committed together if fn returns nil and rolled back
+// otherwise. Calls made with a context which already carries a transaction are run
+// inside a savepoint of that transaction.
+func (r *PostgresRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx or the connection pool if there is none.
+func (r *PostgresRepository) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return r.Db
+}
+
+// withTx runs fn inside a database transaction which is committed if fn succeeds
+// and rolled back otherwise. If ctx already carries a transaction, fn is run inside
+// a savepoint of it.
+func (r *PostgresRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return pgx.BeginFunc(ctx, tx, fn)
+	}
+
+	return pgx.BeginFunc(ctx, r.Db, fn)
+}
+
+// isViolation reports whether err is a PostgreSQL error with the given SQLSTATE code.
+func isViolation(err error, code string) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/repotest"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestRepository connects to the PostgreSQL server at POSTGRES_TEST_URI and returns a migrated
+// repository using a schema of its own, which is dropped when the test finishes.
+// The test is skipped if POSTGRES_TEST_URI is not set.
+func newTestRepository(t *testing.T) *PostgresRepository {
+	t.Helper()
+
+	uri := os.Getenv("POSTGRES_TEST_URI")
+	if uri == "" {
+		t.Skip("POSTGRES_TEST_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schema := fmt.Sprintf("family_test_%d", time.Now().UnixNano())
+
+	admin, err := pgx.Connect(ctx, uri)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+
+	if _, err = admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	poolCfg, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		t.Fatalf("failed to parse POSTGRES_TEST_URI: %v", err)
+	}
+	poolCfg.ConnConfig.RuntimeParams["search_path"] = schema
+
+	db, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		db.Close()
+		_, _ = admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		_ = admin.Close(ctx)
+	})
+
+	repo := &PostgresRepository{
+		Db:     db,
+		Config: &config.PostgresConfig{},
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	if err = repo.migrate(ctx); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return repo
+}
+
+func TestContract(t *testing.T) {
+	repotest.Contract(t, func(t *testing.T) repository.Repository {
+		return newTestRepository(t)
+	})
+}
+
+func TestFamilyConcurrency(t *testing.T) {
+	repotest.FamilyConcurrency(t, func(t *testing.T) repository.FamilyRepository {
+		return newTestRepository(t)
+	})
+}
@@ -3,23 +3,105 @@ package repository
 import (
 	"context"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"time"
 )
 
 type FamilyRepository interface {
-	CreateFamily(ctx context.Context, leaderID int64) (int64, error)
+	CreateFamily(ctx context.Context, leaderID int64, profile models.FamilyProfile) (int64, error)
 	GetFamilyMembersID(ctx context.Context, familyID int64) ([]int64, error)
 	GetFamilyLeaderID(ctx context.Context, familyID int64) (int64, error)
 	IsUserInFamily(ctx context.Context, familyID, userID int64) (bool, error)
 	AddUserToFamily(ctx context.Context, familyID, userID int64) error
 	RemoveUserFromFamily(ctx context.Context, familyID, userID int64) error
 	DeleteFamily(ctx context.Context, familyID int64) ([]int64, error)
+	ListFamilies(ctx context.Context, afterFamilyID int64, limit int) ([]models.Family, error)
+	GetMemberRole(ctx context.Context, familyID, userID int64) (models.FamilyRole, error)
+	SetMemberRole(ctx context.Context, familyID, userID int64, role models.FamilyRole) error
+	GetFamily(ctx context.Context, familyID int64) (models.Family, error)
+	TransferLeadership(ctx context.Context, familyID, fromUserID, toUserID int64) error
+	SetSuccessionPolicy(ctx context.Context, familyID int64, policy models.SuccessionPolicy) error
+	UpdateFamily(ctx context.Context, familyID int64, update models.FamilyUpdate) error
+	ListUserFamilies(ctx context.Context, userID, afterFamilyID int64, limit int) ([]models.Family, error)
+	SearchFamilies(
+		ctx context.Context,
+		filter models.FamilyFilter,
+		after *cursor.Position,
+		limit int,
+	) ([]models.Family, error)
 }
 
 type InviteRepository interface {
-	RegisterInvite(ctx context.Context, familyID, userID int64) (int64, error)
+	RegisterInvite(ctx context.Context, familyID, userID, inviterID int64, expiresAt time.Time) (int64, error)
 	GetInvites(ctx context.Context, userID int64) ([]models.Invite, error)
+	GetFamilyInvites(ctx context.Context, familyID int64) ([]models.Invite, error)
 	IsUserInvited(ctx context.Context, familyID, userID int64) (bool, error)
 	AcceptInvite(ctx context.Context, userID, inviteID int64) (int64, error)
 	DenyInvite(ctx context.Context, userID, inviteID int64) error
+	RevokeInvite(ctx context.Context, familyID, inviteID int64) error
 	DeleteUserInvites(ctx context.Context, userID int64) error
+	DeleteExpiredInvites(ctx context.Context, now time.Time) (int64, error)
+}
+
+type ContactInviteRepository interface {
+	RegisterContactInvite(
+		ctx context.Context,
+		familyID int64,
+		contact string,
+		inviterID int64,
+		expiresAt time.Time,
+	) (int64, error)
+	TakeContactInvites(ctx context.Context, contacts []string) ([]models.ContactInvite, error)
+	GetFamilyContactInvites(ctx context.Context, familyID int64) ([]models.ContactInvite, error)
+	RevokeContactInvite(ctx context.Context, familyID, inviteID int64) error
+}
+
+type JoinCodeRepository interface {
+	CreateJoinCode(ctx context.Context, code models.JoinCode) error
+	GetFamilyJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error)
+	DisableJoinCode(ctx context.Context, familyID int64, code string) error
+	RedeemJoinCode(ctx context.Context, code string) (int64, error)
+}
+
+type OutboxRepository interface {
+	AddOutboxEvents(ctx context.Context, events ...models.OutboxEvent) error
+	ClaimOutboxEvent(
+		ctx context.Context,
+		owner string,
+		now time.Time,
+		lease time.Duration,
+	) (models.OutboxEvent, bool, error)
+	RescheduleOutboxEvent(ctx context.Context, eventID int64, owner string, attempts int, next time.Time) error
+	DeadLetterOutboxEvent(ctx context.Context, eventID int64, owner string, attempts int, reason string) error
+	DeleteOutboxEvent(ctx context.Context, eventID int64) error
+}
+
+type FormerMemberRepository interface {
+	AddFormerMembers(ctx context.Context, familyID int64, userIDs ...int64) error
+	GetFormerMembers(ctx context.Context) ([]models.FormerMember, error)
+	DeleteFormerMembers(ctx context.Context, userID int64, leftBefore time.Time) error
+}
+
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error)
+}
+
+// Transactor runs a unit of work atomically: all repository calls made with the context
+// passed to fn are either committed together when fn returns nil or rolled back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Repository is a storage backend which is able to persist families, invites, join codes, outbox events
+// and former members of families.
+type Repository interface {
+	FamilyRepository
+	InviteRepository
+	ContactInviteRepository
+	JoinCodeRepository
+	OutboxRepository
+	FormerMemberRepository
+	Transactor
 }
@@ -0,0 +1,87 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testFamilyContactInvites checks that the pending invites of a family are listed in the order
+// they were sent and can be revoked only on behalf of the family they were sent to.
+func testFamilyContactInvites(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	familyID, err := repo.CreateFamily(ctx, 1, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	otherID, err := repo.CreateFamily(ctx, 2, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	var want []int64
+
+	for _, contact := range []string{"email:alice@example.com", "phone:375291234567"} {
+		id, err := repo.RegisterContactInvite(ctx, familyID, contact, 1, expiresAt)
+		if err != nil {
+			t.Fatalf("failed to register contact invite: %v", err)
+		}
+		want = append(want, id)
+	}
+
+	otherInviteID, err := repo.RegisterContactInvite(ctx, otherID, "email:alice@example.com", 2, expiresAt)
+	if err != nil {
+		t.Fatalf("failed to register contact invite: %v", err)
+	}
+
+	if got := contactInviteIDs(t, repo, familyID); !slices.Equal(got, want) {
+		t.Fatalf("pending invites %v, want %v", got, want)
+	}
+
+	err = repo.RevokeContactInvite(ctx, familyID, otherInviteID)
+	if !errors.Is(err, grpcerror.ErrInviteNotFound) {
+		t.Fatalf("RevokeContactInvite() of another family's invite error = %v, want %v", err, grpcerror.ErrInviteNotFound)
+	}
+
+	if err = repo.RevokeContactInvite(ctx, familyID, want[0]); err != nil {
+		t.Fatalf("RevokeContactInvite() error = %v", err)
+	}
+
+	if got := contactInviteIDs(t, repo, familyID); !slices.Equal(got, want[1:]) {
+		t.Fatalf("pending invites after revoking %v, want %v", got, want[1:])
+	}
+
+	if got := contactInviteIDs(t, repo, otherID); !slices.Equal(got, []int64{otherInviteID}) {
+		t.Fatalf("pending invites of the other family %v, want %v", got, []int64{otherInviteID})
+	}
+
+	err = repo.RevokeContactInvite(ctx, familyID, want[0])
+	if !errors.Is(err, grpcerror.ErrInviteNotFound) {
+		t.Fatalf("RevokeContactInvite() of a revoked invite error = %v, want %v", err, grpcerror.ErrInviteNotFound)
+	}
+}
+
+// contactInviteIDs returns the IDs of the pending invites of the family in the order they are listed.
+func contactInviteIDs(t *testing.T, repo repository.Repository, familyID int64) []int64 {
+	t.Helper()
+
+	invites, err := repo.GetFamilyContactInvites(context.Background(), familyID)
+	if err != nil {
+		t.Fatalf("failed to get contact invites: %v", err)
+	}
+
+	ids := make([]int64, 0, len(invites))
+	for _, invite := range invites {
+		ids = append(ids, invite.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,244 @@
+// Package repotest contains tests which every repository backend has to pass.
+// The backends run them from their own tests against an empty repository.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// goroutines is the number of concurrent calls made against the same family.
+const goroutines = 50
+
+// FamilyConcurrency hammers a single family with concurrent membership changes
+// and checks that none of them is lost or applied twice. newRepo is called
+// for every subtest and has to return an empty repository.
+func FamilyConcurrency(t *testing.T, newRepo func(t *testing.T) repository.FamilyRepository) {
+	t.Run("AddUserToFamily", func(t *testing.T) {
+		testAddUserToFamilyConcurrently(t, newRepo(t))
+	})
+	t.Run("AddAndRemoveUsers", func(t *testing.T) {
+		testAddAndRemoveUsersConcurrently(t, newRepo(t))
+	})
+	t.Run("LastMemberLeavesWhileUserJoins", func(t *testing.T) {
+		testLastMemberLeavesWhileUserJoins(t, newRepo(t))
+	})
+}
+
+// checkFamily fails the test if the family has duplicate members, its members, member count
+// and member details disagree or it is led by a user who is not its owner. It returns the sorted members of the family.
+func checkFamily(t *testing.T, repo repository.FamilyRepository, familyID int64) []int64 {
+	t.Helper()
+
+	family, err := repo.GetFamily(context.Background(), familyID)
+	if err != nil {
+		t.Fatalf("failed to get family %d: %v", familyID, err)
+	}
+
+	members := slices.Clone(family.MembersID)
+	slices.Sort(members)
+
+	if len(slices.Compact(slices.Clone(members))) != len(members) {
+		t.Fatalf("family %d has duplicate members: %v", familyID, members)
+	}
+
+	if family.MemberCount != len(members) {
+		t.Fatalf("family %d has member count %d, want %d", familyID, family.MemberCount, len(members))
+	}
+
+	details := make([]int64, 0, len(family.Members))
+	for _, member := range family.Members {
+		details = append(details, member.UserID)
+	}
+	slices.Sort(details)
+
+	if !slices.Equal(details, members) {
+		t.Fatalf("family %d has member details of %v, want %v", familyID, details, members)
+	}
+
+	if !slices.Contains(members, family.LeaderUserID) {
+		t.Fatalf("family %d is led by %d, who is not its member", familyID, family.LeaderUserID)
+	}
+
+	if role, _ := family.RoleOf(family.LeaderUserID); role != models.OwnerRole {
+		t.Fatalf("leader of family %d has role %q, want %q", familyID, role, models.OwnerRole)
+	}
+
+	return members
+}
+
+func testAddUserToFamilyConcurrently(t *testing.T, repo repository.FamilyRepository) {
+	ctx := context.Background()
+
+	familyID, err := repo.CreateFamily(ctx, 1, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	want := []int64{1}
+	for i := 0; i < goroutines; i++ {
+		want = append(want, int64(i+2))
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		joined = make(map[int64]int)
+	)
+
+	// every user joins twice, so exactly one of the calls has to be rejected
+	for i := 0; i < 2*goroutines; i++ {
+		userID := int64(i%goroutines + 2)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repo.AddUserToFamily(ctx, familyID, userID)
+			if errors.Is(err, grpcerror.ErrUserInFamily) {
+				return
+			}
+			if err != nil {
+				t.Errorf("failed to add user %d: %v", userID, err)
+				return
+			}
+
+			mu.Lock()
+			joined[userID]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	for _, userID := range want[1:] {
+		if joined[userID] != 1 {
+			t.Fatalf("user %d joined %d times, want 1", userID, joined[userID])
+		}
+	}
+
+	if members := checkFamily(t, repo, familyID); !slices.Equal(members, want) {
+		t.Fatalf("family has members %v, want %v", members, want)
+	}
+}
+
+func testAddAndRemoveUsersConcurrently(t *testing.T, repo repository.FamilyRepository) {
+	ctx := context.Background()
+
+	familyID, err := repo.CreateFamily(ctx, 1, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	// users 2..goroutines+1 are members and leave, users after them join, and the leader leaves too
+	var leaving, joining []int64
+	for i := 0; i < goroutines; i++ {
+		userID := int64(i + 2)
+
+		if err = repo.AddUserToFamily(ctx, familyID, userID); err != nil {
+			t.Fatalf("failed to add user %d: %v", userID, err)
+		}
+
+		leaving = append(leaving, userID)
+		joining = append(joining, userID+goroutines)
+	}
+	leaving = append(leaving, 1)
+
+	var wg sync.WaitGroup
+
+	for _, userID := range leaving {
+		userID := userID
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := repo.RemoveUserFromFamily(ctx, familyID, userID); err != nil {
+				t.Errorf("failed to remove user %d: %v", userID, err)
+			}
+		}()
+	}
+
+	for _, userID := range joining {
+		userID := userID
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repo.AddUserToFamily(ctx, familyID, userID)
+			if err != nil && !errors.Is(err, grpcerror.ErrFamilyNotFound) {
+				t.Errorf("failed to add user %d: %v", userID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	_, err = repo.GetFamilyMembersID(ctx, familyID)
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		// everybody left before the joining users came in
+		return
+	}
+
+	for _, userID := range checkFamily(t, repo, familyID) {
+		if !slices.Contains(joining, userID) {
+			t.Fatalf("user %d is still a member of family %d", userID, familyID)
+		}
+	}
+}
+
+func testLastMemberLeavesWhileUserJoins(t *testing.T, repo repository.FamilyRepository) {
+	ctx := context.Background()
+
+	for i := 0; i < goroutines; i++ {
+		familyID, err := repo.CreateFamily(ctx, 1, models.FamilyProfile{})
+		if err != nil {
+			t.Fatalf("failed to create family: %v", err)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			joinErr error
+		)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			if err := repo.RemoveUserFromFamily(ctx, familyID, 1); err != nil {
+				t.Errorf("failed to remove the leader: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+
+			joinErr = repo.AddUserToFamily(ctx, familyID, 2)
+		}()
+
+		wg.Wait()
+
+		_, err = repo.GetFamilyMembersID(ctx, familyID)
+		switch {
+		case errors.Is(err, grpcerror.ErrFamilyNotFound):
+			if joinErr == nil {
+				t.Fatalf("user joined family %d, which was deleted", familyID)
+			}
+		case err != nil:
+			t.Fatalf("failed to get family: %v", err)
+		default:
+			if joinErr != nil {
+				t.Fatalf("family %d is left without the joining user: %v", familyID, joinErr)
+			}
+			if members := checkFamily(t, repo, familyID); !slices.Equal(members, []int64{2}) {
+				t.Fatalf("family %d has members %v, want [2]", familyID, members)
+			}
+		}
+	}
+}
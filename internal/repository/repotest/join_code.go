@@ -0,0 +1,93 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRedeemJoinCodeConcurrently lets many users redeem a single use join code at once
+// the way the invite service does, redeeming the code and adding the user in one transaction,
+// and checks that exactly one of them becomes a member.
+func testRedeemJoinCodeConcurrently(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	familyID, err := repo.CreateFamily(ctx, 1, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	code := models.JoinCode{
+		Code:      "SINGLEUSE",
+		FamilyID:  familyID,
+		CreatedBy: 1,
+		MaxUses:   1,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if err = repo.CreateJoinCode(ctx, code); err != nil {
+		t.Fatalf("failed to create join code: %v", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		joined []int64
+	)
+
+	for i := 0; i < goroutines; i++ {
+		userID := int64(i + 2)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repo.WithinTransaction(ctx, func(ctx context.Context) error {
+				id, err := repo.RedeemJoinCode(ctx, code.Code)
+				if err != nil {
+					return err
+				}
+
+				return repo.AddUserToFamily(ctx, id, userID)
+			})
+			if errors.Is(err, grpcerror.ErrJoinCodeNotFound) {
+				return
+			}
+			if err != nil {
+				t.Errorf("failed to redeem join code for user %d: %v", userID, err)
+				return
+			}
+
+			mu.Lock()
+			joined = append(joined, userID)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if len(joined) != 1 {
+		t.Fatalf("users %v redeemed the single use code, want exactly one", joined)
+	}
+
+	want := []int64{1, joined[0]}
+	slices.Sort(want)
+
+	if members := checkFamily(t, repo, familyID); !slices.Equal(members, want) {
+		t.Fatalf("family has members %v, want %v", members, want)
+	}
+
+	codes, err := repo.GetFamilyJoinCodes(ctx, familyID)
+	if err != nil {
+		t.Fatalf("failed to get join codes: %v", err)
+	}
+	if len(codes) != 0 {
+		t.Fatalf("used up code is still active: %+v", codes)
+	}
+}
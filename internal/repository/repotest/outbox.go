@@ -0,0 +1,74 @@
+package repotest
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"testing"
+	"time"
+)
+
+// testClaimOutboxEvent checks that events are leased to one owner at a time, that events of the same
+// user and family are claimed in the order they were stored and that dead-lettered events don't hold
+// the later ones back.
+func testClaimOutboxEvent(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	lease := 30 * time.Second
+
+	err := repo.AddOutboxEvents(ctx,
+		models.OutboxEvent{Action: models.AddFamilyAction, UserID: 1, FamilyID: 10, NextAttemptAt: now, CreatedAt: now},
+		models.OutboxEvent{Action: models.RemoveFamilyAction, UserID: 1, FamilyID: 10, NextAttemptAt: now, CreatedAt: now},
+		models.OutboxEvent{Action: models.AddFamilyAction, UserID: 2, FamilyID: 10, NextAttemptAt: now, CreatedAt: now},
+	)
+	if err != nil {
+		t.Fatalf("failed to add outbox events: %v", err)
+	}
+
+	claim := func(owner string, at time.Time) (models.OutboxEvent, bool) {
+		t.Helper()
+
+		event, ok, err := repo.ClaimOutboxEvent(ctx, owner, at, lease)
+		if err != nil {
+			t.Fatalf("failed to claim outbox event: %v", err)
+		}
+
+		return event, ok
+	}
+
+	first, ok := claim("a", now)
+	if !ok || first.UserID != 1 || first.Action != models.AddFamilyAction || first.Owner != "a" {
+		t.Fatalf("first claim = %+v, %v; want the addition of user 1 owned by a", first, ok)
+	}
+
+	other, ok := claim("b", now)
+	if !ok || other.UserID != 2 {
+		t.Fatalf("second claim = %+v, %v; want the event of user 2, the removal of user 1 waits for the addition",
+			other, ok)
+	}
+
+	if event, ok := claim("c", now); ok {
+		t.Fatalf("third claim = %+v; want none, all claimable events are leased", event)
+	}
+
+	if err = repo.RescheduleOutboxEvent(ctx, first.ID, "b", 1, now); err != nil {
+		t.Fatalf("failed to reschedule outbox event: %v", err)
+	}
+	if event, ok := claim("c", now); ok {
+		t.Fatalf("claim after a reschedule by another owner = %+v; want none, the lease is kept", event)
+	}
+
+	if err = repo.DeadLetterOutboxEvent(ctx, first.ID, "a", 1, "not found"); err != nil {
+		t.Fatalf("failed to dead-letter outbox event: %v", err)
+	}
+
+	second, ok := claim("c", now)
+	if !ok || second.UserID != 1 || second.Action != models.RemoveFamilyAction {
+		t.Fatalf("claim after dead-lettering = %+v, %v; want the removal of user 1", second, ok)
+	}
+
+	expired, ok := claim("d", now.Add(lease+time.Second))
+	if !ok || expired.ID != second.ID || expired.Owner != "d" {
+		t.Fatalf("claim after the lease expired = %+v, %v; want the removal of user 1 owned by d", expired, ok)
+	}
+}
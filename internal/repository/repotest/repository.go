@@ -0,0 +1,410 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// missingID is an ID no family or invite of the tests has.
+const missingID = 1_000_000
+
+// errRollback is returned from transactions which have to be rolled back.
+var errRollback = errors.New("rollback")
+
+// Contract checks the semantics every backend has to share. newRepo is called for every subtest
+// and has to return an empty repository issuing IDs from sequences.
+func Contract(t *testing.T, newRepo func(t *testing.T) repository.Repository) {
+	t.Run("SequenceIDs", func(t *testing.T) {
+		testSequenceIDs(t, newRepo(t))
+	})
+	t.Run("RemoveUserFromFamily", func(t *testing.T) {
+		testRemoveUserFromFamily(t, newRepo)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		testNotFound(t, newRepo(t))
+	})
+	t.Run("WithinTransaction", func(t *testing.T) {
+		testWithinTransaction(t, newRepo)
+	})
+	t.Run("ClaimOutboxEvent", func(t *testing.T) {
+		testClaimOutboxEvent(t, newRepo(t))
+	})
+	t.Run("SearchFamilies", func(t *testing.T) {
+		testSearchFamilies(t, newRepo(t))
+	})
+	t.Run("FamilyContactInvites", func(t *testing.T) {
+		testFamilyContactInvites(t, newRepo(t))
+	})
+	t.Run("RedeemJoinCodeConcurrently", func(t *testing.T) {
+		testRedeemJoinCodeConcurrently(t, newRepo(t))
+	})
+}
+
+// createFamily creates a family of the leader and the other members, who join in the given order.
+func createFamily(t *testing.T, repo repository.Repository, leaderID int64, memberIDs ...int64) int64 {
+	t.Helper()
+
+	ctx := context.Background()
+
+	familyID, err := repo.CreateFamily(ctx, leaderID, models.FamilyProfile{})
+	if err != nil {
+		t.Fatalf("failed to create family: %v", err)
+	}
+
+	for _, userID := range memberIDs {
+		if err = repo.AddUserToFamily(ctx, familyID, userID); err != nil {
+			t.Fatalf("failed to add user %d: %v", userID, err)
+		}
+	}
+
+	return familyID
+}
+
+// registerInvite invites the user to the family on behalf of inviterID for a day.
+func registerInvite(t *testing.T, repo repository.Repository, familyID, userID, inviterID int64) int64 {
+	t.Helper()
+
+	inviteID, err := repo.RegisterInvite(context.Background(), familyID, userID, inviterID, time.Now().Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to register invite: %v", err)
+	}
+
+	return inviteID
+}
+
+// inviteIDs returns the IDs of the invites of the user.
+func inviteIDs(t *testing.T, repo repository.Repository, userID int64) []int64 {
+	t.Helper()
+
+	invites, err := repo.GetInvites(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("failed to get invites of user %d: %v", userID, err)
+	}
+
+	ids := make([]int64, 0, len(invites))
+	for _, invite := range invites {
+		ids = append(ids, invite.ID)
+	}
+
+	return ids
+}
+
+func testSequenceIDs(t *testing.T, repo repository.Repository) {
+	var familyIDs, inviteIDs []int64
+
+	for i := int64(1); i <= 3; i++ {
+		familyID := createFamily(t, repo, i)
+		familyIDs = append(familyIDs, familyID)
+		inviteIDs = append(inviteIDs, registerInvite(t, repo, familyID, i+10, i))
+	}
+
+	// families and invites are counted by separate sequences
+	if want := []int64{1, 2, 3}; !slices.Equal(familyIDs, want) {
+		t.Fatalf("families got IDs %v, want %v", familyIDs, want)
+	}
+	if want := []int64{1, 2, 3}; !slices.Equal(inviteIDs, want) {
+		t.Fatalf("invites got IDs %v, want %v", inviteIDs, want)
+	}
+}
+
+func testRemoveUserFromFamily(t *testing.T, newRepo func(t *testing.T) repository.Repository) {
+	tests := []struct {
+		name        string
+		members     []int64
+		remove      int64
+		wantLeader  int64
+		wantMembers []int64
+	}{
+		{
+			name:        "member leaves",
+			members:     []int64{2, 3},
+			remove:      3,
+			wantLeader:  1,
+			wantMembers: []int64{1, 2},
+		},
+		{
+			name:        "leader leaves",
+			members:     []int64{2, 3},
+			remove:      1,
+			wantLeader:  2,
+			wantMembers: []int64{2, 3},
+		},
+		{
+			name:        "leader passes to the member who joined first",
+			members:     []int64{5, 4, 3},
+			remove:      1,
+			wantLeader:  5,
+			wantMembers: []int64{3, 4, 5},
+		},
+		{
+			name:        "user is not a member",
+			members:     []int64{2},
+			remove:      3,
+			wantLeader:  1,
+			wantMembers: []int64{1, 2},
+		},
+		{
+			name:   "last member leaves",
+			remove: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+
+			familyID := createFamily(t, repo, 1, tt.members...)
+
+			if err := repo.RemoveUserFromFamily(ctx, familyID, tt.remove); err != nil {
+				t.Fatalf("failed to remove user: %v", err)
+			}
+
+			family, err := repo.GetFamily(ctx, familyID)
+			if tt.wantMembers == nil {
+				if !errors.Is(err, grpcerror.ErrFamilyNotFound) {
+					t.Fatalf("got family %+v and error %v, want ErrFamilyNotFound", family, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if family.LeaderUserID != tt.wantLeader {
+				t.Fatalf("family is led by %d, want %d", family.LeaderUserID, tt.wantLeader)
+			}
+
+			if members := checkFamily(t, repo, familyID); !slices.Equal(members, tt.wantMembers) {
+				t.Fatalf("family has members %v, want %v", members, tt.wantMembers)
+			}
+		})
+	}
+}
+
+func testNotFound(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	familyID := createFamily(t, repo, 1, 2)
+	inviteID := registerInvite(t, repo, familyID, 3, 1)
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{
+			name: "GetFamily",
+			call: func() error {
+				_, err := repo.GetFamily(ctx, missingID)
+				return err
+			},
+			want: grpcerror.ErrFamilyNotFound,
+		},
+		{
+			name: "GetFamilyMembersID",
+			call: func() error {
+				_, err := repo.GetFamilyMembersID(ctx, missingID)
+				return err
+			},
+			want: grpcerror.ErrFamilyNotFound,
+		},
+		{
+			name: "AddUserToFamily",
+			call: func() error {
+				return repo.AddUserToFamily(ctx, missingID, 3)
+			},
+			want: grpcerror.ErrFamilyNotFound,
+		},
+		{
+			name: "RemoveUserFromFamily",
+			call: func() error {
+				return repo.RemoveUserFromFamily(ctx, missingID, 1)
+			},
+			want: grpcerror.ErrFamilyNotFound,
+		},
+		{
+			name: "DeleteFamily",
+			call: func() error {
+				_, err := repo.DeleteFamily(ctx, missingID)
+				return err
+			},
+			want: grpcerror.ErrFamilyNotFound,
+		},
+		{
+			name: "AcceptInvite of a missing invite",
+			call: func() error {
+				_, err := repo.AcceptInvite(ctx, 3, missingID)
+				return err
+			},
+			want: grpcerror.ErrInviteNotFound,
+		},
+		{
+			name: "AcceptInvite of another user's invite",
+			call: func() error {
+				_, err := repo.AcceptInvite(ctx, 4, inviteID)
+				return err
+			},
+			want: grpcerror.ErrInviteNotFound,
+		},
+		{
+			name: "DenyInvite of another user's invite",
+			call: func() error {
+				return repo.DenyInvite(ctx, 4, inviteID)
+			},
+			want: grpcerror.ErrInviteNotFound,
+		},
+		{
+			name: "RevokeInvite of another family",
+			call: func() error {
+				return repo.RevokeInvite(ctx, missingID, inviteID)
+			},
+			want: grpcerror.ErrInviteNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// the calls above must not have touched the existing family and invite
+	if members := checkFamily(t, repo, familyID); !slices.Equal(members, []int64{1, 2}) {
+		t.Fatalf("family has members %v, want [1 2]", members)
+	}
+	if ids := inviteIDs(t, repo, 3); !slices.Equal(ids, []int64{inviteID}) {
+		t.Fatalf("user has invites %v, want [%d]", ids, inviteID)
+	}
+}
+
+func testWithinTransaction(t *testing.T, newRepo func(t *testing.T) repository.Repository) {
+	tests := []struct {
+		name string
+		// fn changes the family of users 1 and 2 and the invite of user 3 to it
+		fn func(ctx context.Context, repo repository.Repository, familyID, inviteID int64) error
+		// wantMembers are the members of the family after a commit, nil if it is deleted
+		wantMembers []int64
+		wantInvites bool
+	}{
+		{
+			name: "accept invite",
+			fn: func(ctx context.Context, repo repository.Repository, familyID, inviteID int64) error {
+				if _, err := repo.AcceptInvite(ctx, 3, inviteID); err != nil {
+					return err
+				}
+				return repo.AddUserToFamily(ctx, familyID, 3)
+			},
+			wantMembers: []int64{1, 2, 3},
+		},
+		{
+			name: "leader leaves",
+			fn: func(ctx context.Context, repo repository.Repository, familyID, _ int64) error {
+				return repo.RemoveUserFromFamily(ctx, familyID, 1)
+			},
+			wantMembers: []int64{2},
+			wantInvites: true,
+		},
+		{
+			name: "delete family and invites",
+			fn: func(ctx context.Context, repo repository.Repository, familyID, _ int64) error {
+				if _, err := repo.DeleteFamily(ctx, familyID); err != nil {
+					return err
+				}
+				return repo.DeleteUserInvites(ctx, 3)
+			},
+		},
+		{
+			name: "create another family",
+			fn: func(ctx context.Context, repo repository.Repository, familyID, _ int64) error {
+				otherID, err := repo.CreateFamily(ctx, 3, models.FamilyProfile{})
+				if err != nil {
+					return err
+				}
+				return repo.AddUserToFamily(ctx, otherID, 1)
+			},
+			wantMembers: []int64{1, 2},
+			wantInvites: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name+" and roll back", func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+
+			familyID := createFamily(t, repo, 1, 2)
+			inviteID := registerInvite(t, repo, familyID, 3, 1)
+
+			err := repo.WithinTransaction(ctx, func(ctx context.Context) error {
+				if err := tt.fn(ctx, repo, familyID, inviteID); err != nil {
+					t.Fatalf("failed to change the family: %v", err)
+				}
+				return errRollback
+			})
+			if !errors.Is(err, errRollback) {
+				t.Fatalf("got error %v, want %v", err, errRollback)
+			}
+
+			family, err := repo.GetFamily(ctx, familyID)
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+			if family.LeaderUserID != 1 {
+				t.Fatalf("family is led by %d, want 1", family.LeaderUserID)
+			}
+			if members := checkFamily(t, repo, familyID); !slices.Equal(members, []int64{1, 2}) {
+				t.Fatalf("family has members %v, want [1 2]", members)
+			}
+			if ids := inviteIDs(t, repo, 3); !slices.Equal(ids, []int64{inviteID}) {
+				t.Fatalf("user has invites %v, want [%d]", ids, inviteID)
+			}
+
+			families, err := repo.ListFamilies(ctx, 0, 10)
+			if err != nil {
+				t.Fatalf("failed to list families: %v", err)
+			}
+			if len(families) != 1 {
+				t.Fatalf("there are %d families, want 1", len(families))
+			}
+		})
+
+		t.Run(tt.name+" and commit", func(t *testing.T) {
+			repo := newRepo(t)
+			ctx := context.Background()
+
+			familyID := createFamily(t, repo, 1, 2)
+			inviteID := registerInvite(t, repo, familyID, 3, 1)
+
+			err := repo.WithinTransaction(ctx, func(ctx context.Context) error {
+				return tt.fn(ctx, repo, familyID, inviteID)
+			})
+			if err != nil {
+				t.Fatalf("failed to commit: %v", err)
+			}
+
+			_, err = repo.GetFamily(ctx, familyID)
+			if tt.wantMembers == nil {
+				if !errors.Is(err, grpcerror.ErrFamilyNotFound) {
+					t.Fatalf("got error %v, want ErrFamilyNotFound", err)
+				}
+			} else if members := checkFamily(t, repo, familyID); !slices.Equal(members, tt.wantMembers) {
+				t.Fatalf("family has members %v, want %v", members, tt.wantMembers)
+			}
+
+			if ids := inviteIDs(t, repo, 3); (len(ids) > 0) != tt.wantInvites {
+				t.Fatalf("user has invites %v, want any: %t", ids, tt.wantInvites)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package repotest
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"slices"
+	"testing"
+)
+
+// testSearchFamilies pages through families sorted by a key many of them share, checking that
+// ties are broken by ID in the direction of the sort, so no family is skipped or repeated.
+func testSearchFamilies(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	two1 := createFamily(t, repo, 1, 2)
+	one1 := createFamily(t, repo, 3)
+	two2 := createFamily(t, repo, 4, 5)
+	two3 := createFamily(t, repo, 6, 7)
+	one2 := createFamily(t, repo, 8)
+
+	tests := []struct {
+		name   string
+		filter models.FamilyFilter
+		want   []int64
+	}{
+		{
+			name:   "by id",
+			filter: models.FamilyFilter{SortBy: models.SortByID},
+			want:   []int64{two1, one1, two2, two3, one2},
+		},
+		{
+			name:   "by id descending",
+			filter: models.FamilyFilter{SortBy: models.SortByID, Descending: true},
+			want:   []int64{one2, two3, two2, one1, two1},
+		},
+		{
+			name:   "by member count",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount},
+			want:   []int64{one1, one2, two1, two2, two3},
+		},
+		{
+			name:   "by member count descending",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount, Descending: true},
+			want:   []int64{two3, two2, two1, one2, one1},
+		},
+		{
+			name:   "by member count with filter",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount, MinMembers: 2},
+			want:   []int64{two1, two2, two3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got   []int64
+				after *cursor.Position
+			)
+
+			for page := 0; page <= len(tt.want); page++ {
+				families, err := repo.SearchFamilies(ctx, tt.filter, after, 2)
+				if err != nil {
+					t.Fatalf("failed to search families: %v", err)
+				}
+				if len(families) == 0 {
+					break
+				}
+
+				for _, family := range families {
+					got = append(got, family.ID)
+				}
+
+				last := families[len(families)-1]
+				after = &cursor.Position{Key: last.SortKey(tt.filter.SortBy), ID: last.ID}
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("families %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,75 +3,177 @@ package family
 import (
 	"context"
 	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
 	"log/slog"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
 type FamilyService struct {
-	log     *slog.Logger
-	repo    repository.FamilyRepository
-	manager *jwt.Manager
+	log        *slog.Logger
+	repo       repository.FamilyRepository
+	outboxRepo repository.OutboxRepository
+	formerRepo repository.FormerMemberRepository
+	tx         repository.Transactor
+	succession services.Succession
 }
 
 func New(
 	log *slog.Logger,
 	repo repository.FamilyRepository,
-	manager *jwt.Manager,
+	outboxRepo repository.OutboxRepository,
+	formerRepo repository.FormerMemberRepository,
+	tx repository.Transactor,
+	succession services.Succession,
 ) *FamilyService {
-	return &FamilyService{log: log, repo: repo, manager: manager}
+	return &FamilyService{
+		log:        log,
+		repo:       repo,
+		outboxRepo: outboxRepo,
+		formerRepo: formerRepo,
+		tx:         tx,
+		succession: succession,
+	}
 }
 
-// CreateFamily creates a new family with the user making the request as the leader.
+// CreateFamily creates a new family with the given profile and the user making the request as the leader.
 // It retrieves the user ID from the context and uses it as the leader ID when creating the family.
-func (s *FamilyService) CreateFamily(ctx context.Context) (int64, error) {
+// The event adding the family to the user's SSO family list is stored in the same transaction.
+func (s *FamilyService) CreateFamily(ctx context.Context, profile models.FamilyProfile) (models.Family, error) {
 	const op = "family.service.CreateFamily"
 
-	userID := s.manager.GetUserIDFromContext(ctx)
+	var family models.Family
+
+	if err := profile.Validate(); err != nil {
+		s.log.With(slog.String("op", op)).Warn("invalid family profile", sl.Err(err))
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	id, err := s.repo.CreateFamily(ctx, userID)
+	principal, err := jwt.PrincipalFromContext(ctx)
 	if err != nil {
-		return -1, fmt.Errorf("%s: %w", op, err)
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		id, err := s.repo.CreateFamily(ctx, principal.UserID, profile)
+		if err != nil {
+			return err
+		}
+
+		family, err = s.repo.GetFamily(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.outboxRepo.AddOutboxEvents(ctx,
+			models.NewOutboxEvent(models.AddFamilyAction, principal.UserID, id))
+	})
+	if err != nil {
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return id, nil
+	return family, nil
 }
 
-// GetFamilyMembersIDs retrieves the IDs of members belonging to the specified family.
+// GetFamily retrieves the family with its profile and members.
 // It first checks if the user making the request is a member of the family.
 // If the user is not a member of the family, it returns a permission denied error.
-func (s *FamilyService) GetFamilyMembersIDs(ctx context.Context, familyID int64) ([]int64, error) {
-	const op = "family.service.GetFamilyMembersIDs"
+func (s *FamilyService) GetFamily(ctx context.Context, familyID int64) (models.Family, error) {
+	const op = "family.service.GetFamily"
 
 	log := s.log.With(
 		slog.String("op", op),
 	)
 
-	userID := s.manager.GetUserIDFromContext(ctx)
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	inFamily, err := s.repo.IsUserInFamily(ctx, familyID, userID)
+	family, err := s.repo.GetFamily(ctx, familyID)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if !inFamily {
+	if _, ok := family.RoleOf(principal.UserID); !ok {
 		log.Warn(grpcerror.ErrForbidden.Error())
-		return nil, grpcerror.ErrForbidden
+		return models.Family{}, grpcerror.ErrForbidden
+	}
+
+	return family, nil
+}
+
+// ListMyFamilies retrieves a page of the families the user making the request is a member of,
+// ordered by their IDs, together with the user's role in each of them.
+// A non-positive limit selects the default page size, larger limits are capped by the maximum one.
+// The returned cursor points to the next page and is empty if there are no more families.
+func (s *FamilyService) ListMyFamilies(
+	ctx context.Context,
+	pageCursor string,
+	limit int,
+) ([]models.FamilySummary, string, error) {
+	const op = "family.service.ListMyFamilies"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	afterID, err := cursor.Decode(pageCursor)
+	if err != nil {
+		log.Warn("failed to decode cursor", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if limit <= 0 {
+		limit = defaultPageSize
 	}
+	limit = min(limit, maxPageSize)
 
-	IDs, err := s.repo.GetFamilyMembersID(ctx, familyID)
+	// One extra family tells whether there is a next page.
+	families, err := s.repo.ListUserFamilies(ctx, principal.UserID, afterID, limit+1)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	return IDs, nil
+	var next string
+
+	if len(families) > limit {
+		families = families[:limit]
+		next = cursor.Encode(families[limit-1].ID)
+	}
+
+	summaries := make([]models.FamilySummary, 0, len(families))
+
+	for _, family := range families {
+		role, _ := family.RoleOf(principal.UserID)
+		summaries = append(summaries, models.FamilySummary{Family: family, Role: role})
+	}
+
+	return summaries, next, nil
 }
 
 // LeaveFamily allows a user to leave a family.
 // It first checks if the user making the request is a member of the specified family.
 // If the user is not a member of the family, it returns a user not in family error.
-// If the user is a member of the family, it removes the user from the family.
+// If the user is the leader, the successor is chosen by the family's succession policy,
+// which may also forbid the leader to leave until the leadership is transferred.
+// Then it removes the user from the family, records the user as its former member and stores
+// the event removing the family from the user's SSO family list. The successor is chosen
+// in the same transaction, from the members the family has when the leadership is transferred.
 func (s *FamilyService) LeaveFamily(ctx context.Context, familyID int64) (int64, error) {
 	const op = "family.service.LeaveFamily"
 
@@ -79,7 +181,12 @@ func (s *FamilyService) LeaveFamily(ctx context.Context, familyID int64) (int64,
 		slog.String("op", op),
 	)
 
-	userID := s.manager.GetUserIDFromContext(ctx)
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	userID := principal.UserID
 
 	inFamily, err := s.repo.IsUserInFamily(ctx, familyID, userID)
 	if err != nil {
@@ -93,7 +200,35 @@ func (s *FamilyService) LeaveFamily(ctx context.Context, familyID int64) (int64,
 		return -1, fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
 	}
 
-	err = s.repo.RemoveUserFromFamily(ctx, familyID, userID)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		family, err := s.repo.GetFamily(ctx, familyID)
+		if err != nil {
+			return err
+		}
+
+		successorID, err := s.succession.Successor(ctx, family, userID)
+		if err != nil {
+			return err
+		}
+
+		if successorID != 0 {
+			err := s.repo.TransferLeadership(ctx, familyID, userID, successorID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.RemoveUserFromFamily(ctx, familyID, userID); err != nil {
+			return err
+		}
+
+		if err := s.formerRepo.AddFormerMembers(ctx, familyID, userID); err != nil {
+			return err
+		}
+
+		return s.outboxRepo.AddOutboxEvents(ctx,
+			models.NewOutboxEvent(models.RemoveFamilyAction, userID, familyID))
+	})
 	if err != nil {
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
@@ -2,11 +2,14 @@ package familyleader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
 	"log/slog"
 )
 
@@ -14,29 +17,41 @@ type FamilyLeaderService struct {
 	log        *slog.Logger
 	familyRepo repository.FamilyRepository
 	inviteRepo repository.InviteRepository
-	manager    *jwt.Manager
+	outboxRepo repository.OutboxRepository
+	formerRepo repository.FormerMemberRepository
+	tx         repository.Transactor
+	succession services.Succession
 }
 
 func New(
 	log *slog.Logger,
 	familyRepo repository.FamilyRepository,
 	inviteRepo repository.InviteRepository,
-	manager *jwt.Manager,
+	outboxRepo repository.OutboxRepository,
+	formerRepo repository.FormerMemberRepository,
+	tx repository.Transactor,
+	succession services.Succession,
 ) *FamilyLeaderService {
 	return &FamilyLeaderService{
 		log:        log,
 		familyRepo: familyRepo,
 		inviteRepo: inviteRepo,
-		manager:    manager,
+		outboxRepo: outboxRepo,
+		formerRepo: formerRepo,
+		tx:         tx,
+		succession: succession,
 	}
 }
 
-// RemoveUserFromFamily allows a family leader to remove a user from the family.
-// It first checks if the caller has the rights to remove users from the family.
-// If the caller does not have the rights (is not the family leader or admin), it returns a forbidden error.
+// RemoveUserFromFamily allows the owner or a co-leader of the family to remove a user from the family.
+// It first checks if the caller's role grants the right to remove members.
+// If it does not (and the caller is not an admin), it returns a forbidden error.
 // If the caller has the rights, it checks if the specified user is a member of the family.
 // If the user is not a member of the family, it returns a user not in family error.
-// If the user is a member of the family, it removes the user from the family.
+// Members can be removed only by callers with a higher role, e.g. a co-leader can't remove another co-leader.
+// If the user is the leader, the successor is chosen by the family's succession policy.
+// If all checks pass, it removes the user from the family, records the user as its former member
+// and stores the event removing the family from the user's SSO family list in the same transaction.
 func (s *FamilyLeaderService) RemoveUserFromFamily(
 	ctx context.Context,
 	familyID, userID int64) error {
@@ -46,31 +61,66 @@ func (s *FamilyLeaderService) RemoveUserFromFamily(
 		slog.String("op", op),
 	)
 
-	isLeader, err := s.hasRightsToRemove(ctx, familyID)
+	principal, role, err := s.callerRole(ctx, familyID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if !isLeader {
+	if !principal.IsAdmin() && !role.Can(models.RemoveMemberPermission) {
 		log.Warn("failed to remove user",
 			sl.Err(grpcerror.ErrForbidden),
 			slog.Int64("user_id", userID))
 		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
 	}
 
-	inFamily, err := s.familyRepo.IsUserInFamily(ctx, familyID, userID)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	if !inFamily {
+	targetRole, err := s.familyRepo.GetMemberRole(ctx, familyID, userID)
+	if errors.Is(err, grpcerror.ErrUserNotInFamily) {
 		log.Warn(grpcerror.ErrUserNotInFamily.Error(),
 			slog.Int64("family_id", familyID),
 			slog.Int64("user_id", userID))
 		return fmt.Errorf("%s: %w", op, grpcerror.ErrUserNotInFamily)
 	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && principal.UserID != userID && !role.Outranks(targetRole) {
+		log.Warn("failed to remove user",
+			sl.Err(grpcerror.ErrForbidden),
+			slog.Int64("user_id", userID),
+			slog.String("role", string(targetRole)))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		family, err := s.familyRepo.GetFamily(ctx, familyID)
+		if err != nil {
+			return err
+		}
+
+		successorID, err := s.succession.Successor(ctx, family, userID)
+		if err != nil {
+			return err
+		}
+
+		if successorID != 0 {
+			err := s.familyRepo.TransferLeadership(ctx, familyID, userID, successorID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.familyRepo.RemoveUserFromFamily(ctx, familyID, userID); err != nil {
+			return err
+		}
 
-	err = s.familyRepo.RemoveUserFromFamily(ctx, familyID, userID)
+		if err := s.formerRepo.AddFormerMembers(ctx, familyID, userID); err != nil {
+			return err
+		}
+
+		return s.outboxRepo.AddOutboxEvents(ctx,
+			models.NewOutboxEvent(models.RemoveFamilyAction, userID, familyID))
+	})
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -78,10 +128,11 @@ func (s *FamilyLeaderService) RemoveUserFromFamily(
 	return nil
 }
 
-// DeleteFamily allows a family leader to delete the specified family.
+// DeleteFamily allows the owner of the family to delete it.
 // It first checks if the caller has the rights to delete the family.
-// If the caller does not have the rights (is not the family leader or admin), it returns a forbidden error.
-// If the caller has the rights, it deletes the family and removes its ID from users' family lists.
+// If the caller does not have the rights (is not the family owner or admin), it returns a forbidden error.
+// If the caller has the rights, it deletes the family, records its members as former members
+// and stores the events removing its ID from their SSO family lists in the same transaction.
 func (s *FamilyLeaderService) DeleteFamily(
 	ctx context.Context,
 	familyID int64,
@@ -92,28 +143,231 @@ func (s *FamilyLeaderService) DeleteFamily(
 		slog.String("op", op),
 	)
 
-	isLeader, err := s.hasRightsToRemove(ctx, familyID)
+	principal, role, err := s.callerRole(ctx, familyID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if isLeader {
+	if !principal.IsAdmin() && !role.Can(models.DeleteFamilyPermission) {
 		log.Warn("failed to delete family", sl.Err(grpcerror.ErrForbidden))
 		return nil, fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
 	}
 
-	return s.familyRepo.DeleteFamily(ctx, familyID)
+	var members []int64
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		members, err = s.familyRepo.DeleteFamily(ctx, familyID)
+		if err != nil {
+			return err
+		}
+
+		if err = s.formerRepo.AddFormerMembers(ctx, familyID, members...); err != nil {
+			return err
+		}
+
+		events := make([]models.OutboxEvent, 0, len(members))
+		for _, memberID := range members {
+			events = append(events,
+				models.NewOutboxEvent(models.RemoveFamilyAction, memberID, familyID))
+		}
+
+		return s.outboxRepo.AddOutboxEvents(ctx, events...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
 }
 
-func (s *FamilyLeaderService) hasRightsToRemove(ctx context.Context, familyID int64) (bool, error) {
-	const op = "familyleader.service.hasRightsToRemove"
+// SetMemberRole changes the role of a member of the family. Only the owner of the family
+// or an admin can manage roles. The owner role can't be assigned or taken away this way,
+// as it belongs to the leader of the family.
+func (s *FamilyLeaderService) SetMemberRole(
+	ctx context.Context,
+	familyID, userID int64,
+	newRole models.FamilyRole,
+) error {
+	const op = "familyleader.service.SetMemberRole"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
 
-	userID := s.manager.GetUserIDFromContext(ctx)
+	if !newRole.IsAssignable() {
+		log.Warn(grpcerror.ErrInvalidRole.Error(), slog.String("role", string(newRole)))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrInvalidRole)
+	}
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.ManageRolesPermission) {
+		log.Warn("failed to set member role", sl.Err(grpcerror.ErrForbidden))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	targetRole, err := s.familyRepo.GetMemberRole(ctx, familyID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if targetRole == models.OwnerRole {
+		log.Warn("owner's role can't be changed", slog.Int64("user_id", userID))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	if err = s.familyRepo.SetMemberRole(ctx, familyID, userID, newRole); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("member role changed",
+		slog.Int64("family_id", familyID),
+		slog.Int64("user_id", userID),
+		slog.String("role", string(newRole)))
+
+	return nil
+}
+
+// TransferLeadership hands the leadership of the family over to the member with the given ID.
+// Only the current leader of the family or an admin can transfer the leadership.
+// The new leader becomes the owner of the family and the previous one a co-leader.
+func (s *FamilyLeaderService) TransferLeadership(ctx context.Context, familyID, userID int64) error {
+	const op = "familyleader.service.TransferLeadership"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
 	leaderID, err := s.familyRepo.GetFamilyLeaderID(ctx, familyID)
 	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if principal.UserID != leaderID && !principal.IsAdmin() {
+		log.Warn("failed to transfer leadership", sl.Err(grpcerror.ErrForbidden))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	if userID == leaderID {
+		return nil
+	}
+
+	if err = s.familyRepo.TransferLeadership(ctx, familyID, leaderID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("leadership transferred",
+		slog.Int64("family_id", familyID),
+		slog.Int64("from_user_id", leaderID),
+		slog.Int64("to_user_id", userID))
+
+	return nil
+}
+
+// SetSuccessionPolicy changes who becomes the leader of the family when the leader leaves it.
+// Only the owner of the family or an admin can change the policy.
+func (s *FamilyLeaderService) SetSuccessionPolicy(
+	ctx context.Context,
+	familyID int64,
+	policy models.SuccessionPolicy,
+) error {
+	const op = "familyleader.service.SetSuccessionPolicy"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if !policy.IsValid() {
+		log.Warn(grpcerror.ErrInvalidPolicy.Error(), slog.String("policy", string(policy)))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrInvalidPolicy)
+	}
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.ManageFamilyPermission) {
+		log.Warn("failed to set succession policy", sl.Err(grpcerror.ErrForbidden))
+		return fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	if err = s.familyRepo.SetSuccessionPolicy(ctx, familyID, policy); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UpdateFamily changes the profile of the family and returns the updated family.
+// Only the leader of the family or an admin can update it.
+func (s *FamilyLeaderService) UpdateFamily(
+	ctx context.Context,
+	familyID int64,
+	update models.FamilyUpdate,
+) (models.Family, error) {
+	const op = "familyleader.service.UpdateFamily"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if err := update.Validate(); err != nil {
+		log.Warn("invalid family profile", sl.Err(err))
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.ManageFamilyPermission) {
+		log.Warn("failed to update family", sl.Err(grpcerror.ErrForbidden))
+		return models.Family{}, fmt.Errorf("%s: %w", op, grpcerror.ErrForbidden)
+	}
+
+	if !update.IsEmpty() {
+		if err = s.familyRepo.UpdateFamily(ctx, familyID, update); err != nil {
+			return models.Family{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	family, err := s.familyRepo.GetFamily(ctx, familyID)
+	if err != nil {
+		return models.Family{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return family, nil
+}
+
+// callerRole returns the principal of the caller and their role in the family.
+// The role is empty if the caller is not a member of the family, so it grants no permissions.
+func (s *FamilyLeaderService) callerRole(
+	ctx context.Context,
+	familyID int64,
+) (jwt.Principal, models.FamilyRole, error) {
+	const op = "familyleader.service.callerRole"
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return jwt.Principal{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, err := s.familyRepo.GetMemberRole(ctx, familyID, principal.UserID)
+	if err != nil && !errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return jwt.Principal{}, "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	return userID == leaderID || s.manager.IsAdmin(ctx), nil
+	return principal, role, nil
 }
@@ -0,0 +1,100 @@
+package familysearch
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"log/slog"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// FamilySearchService lets admins browse all families of the service.
+type FamilySearchService struct {
+	log  *slog.Logger
+	repo repository.FamilyRepository
+}
+
+func New(
+	log *slog.Logger,
+	repo repository.FamilyRepository,
+) *FamilySearchService {
+	return &FamilySearchService{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// SearchFamilies retrieves a page of the families passing the filter in the order it requests,
+// by family ID when no sort field is set.
+// A non-positive limit selects the default page size, larger limits are capped by the maximum one.
+// The returned cursor points to the next page and is empty if there are no more families;
+// it is only valid with the same sort field and order.
+func (s *FamilySearchService) SearchFamilies(
+	ctx context.Context,
+	filter models.FamilyFilter,
+	pageCursor string,
+	limit int,
+) ([]models.Family, string, error) {
+	const op = "familysearch.service.SearchFamilies"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if filter.SortBy == "" {
+		filter.SortBy = models.SortByID
+	}
+
+	if err := filter.Validate(); err != nil {
+		log.Warn("invalid family filter", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, err := cursor.DecodePosition(pageCursor, sortName(filter))
+	if err != nil {
+		log.Warn("failed to decode cursor", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	// One extra family tells whether there is a next page.
+	families, err := s.repo.SearchFamilies(ctx, filter, after, limit+1)
+	if err != nil {
+		log.Error("failed to search families", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var next string
+
+	if len(families) > limit {
+		families = families[:limit]
+		last := families[limit-1]
+		next = cursor.EncodePosition(cursor.Position{
+			Sort: sortName(filter),
+			Key:  last.SortKey(filter.SortBy),
+			ID:   last.ID,
+		})
+	}
+
+	return families, next, nil
+}
+
+// sortName identifies the order of the filter in cursors.
+func sortName(filter models.FamilyFilter) string {
+	if filter.Descending {
+		return string(filter.SortBy) + "-desc"
+	}
+
+	return string(filter.SortBy)
+}
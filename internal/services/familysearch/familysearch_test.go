@@ -0,0 +1,166 @@
+package familysearch
+
+import (
+	"context"
+	"errors"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cursor"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository/memory"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// newTestService returns a service over an in-memory repository holding families with
+// the given numbers of members. It also returns the IDs of the families in the order they were created.
+func newTestService(t *testing.T, sizes ...int) (*FamilySearchService, []int64) {
+	t.Helper()
+
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repo, err := memory.InitMemoryRepository(&config.IDGeneratorConfig{Kind: config.IDSequence}, log)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var (
+		ids    []int64
+		userID int64
+	)
+
+	for _, size := range sizes {
+		userID++
+
+		familyID, err := repo.CreateFamily(ctx, userID, models.FamilyProfile{})
+		if err != nil {
+			t.Fatalf("failed to create family: %v", err)
+		}
+
+		for i := 1; i < size; i++ {
+			userID++
+
+			if err = repo.AddUserToFamily(ctx, familyID, userID); err != nil {
+				t.Fatalf("failed to add user: %v", err)
+			}
+		}
+
+		ids = append(ids, familyID)
+	}
+
+	return New(log, repo), ids
+}
+
+func TestSearchFamiliesPages(t *testing.T) {
+	s, ids := newTestService(t, 2, 1, 2, 2, 1)
+
+	tests := []struct {
+		name   string
+		filter models.FamilyFilter
+		want   []int64
+	}{
+		{
+			name: "default sort",
+			want: ids,
+		},
+		{
+			name:   "member count with ties",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount},
+			want:   []int64{ids[1], ids[4], ids[0], ids[2], ids[3]},
+		},
+		{
+			name:   "member count descending",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount, Descending: true},
+			want:   []int64{ids[3], ids[2], ids[0], ids[4], ids[1]},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got  []int64
+				next string
+			)
+
+			for page := 0; page <= len(tt.want); page++ {
+				families, cur, err := s.SearchFamilies(context.Background(), tt.filter, next, 2)
+				if err != nil {
+					t.Fatalf("SearchFamilies() error = %v", err)
+				}
+
+				for _, family := range families {
+					got = append(got, family.ID)
+				}
+
+				if cur == "" {
+					break
+				}
+				next = cur
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("families %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchFamiliesRejectsForeignCursors(t *testing.T) {
+	s, _ := newTestService(t, 2, 1, 2)
+	ctx := context.Background()
+
+	byCount := models.FamilyFilter{SortBy: models.SortByMemberCount}
+
+	_, next, err := s.SearchFamilies(ctx, byCount, "", 1)
+	if err != nil || next == "" {
+		t.Fatalf("SearchFamilies() = %q, %v; want a cursor to the next page", next, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter models.FamilyFilter
+		cursor string
+	}{
+		{
+			name:   "cursor of another sort field",
+			filter: models.FamilyFilter{SortBy: models.SortByCreatedAt},
+			cursor: next,
+		},
+		{
+			name:   "cursor of the other direction",
+			filter: models.FamilyFilter{SortBy: models.SortByMemberCount, Descending: true},
+			cursor: next,
+		},
+		{
+			name:   "id cursor",
+			filter: byCount,
+			cursor: cursor.Encode(1),
+		},
+		{
+			name:   "tampered cursor",
+			filter: byCount,
+			cursor: next[:len(next)-2] + "!!",
+		},
+		{
+			name:   "truncated cursor",
+			filter: byCount,
+			cursor: next[:len(next)/2],
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.SearchFamilies(ctx, tt.filter, tt.cursor, 1)
+			if !errors.Is(err, grpcerror.ErrInvalidCursor) {
+				t.Fatalf("SearchFamilies() error = %v, want %v", err, grpcerror.ErrInvalidCursor)
+			}
+		})
+	}
+}
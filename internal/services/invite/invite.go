@@ -2,41 +2,63 @@ package invite
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
 	"log/slog"
+	"time"
 )
 
 type InviteService struct {
-	log        *slog.Logger
-	inviteRepo repository.InviteRepository
-	familyRepo repository.FamilyRepository
-	manager    *jwt.Manager
+	log          *slog.Logger
+	inviteRepo   repository.InviteRepository
+	contactRepo  repository.ContactInviteRepository
+	familyRepo   repository.FamilyRepository
+	joinCodeRepo repository.JoinCodeRepository
+	outboxRepo   repository.OutboxRepository
+	tx           repository.Transactor
+	sso          services.SSO
+	cfg          *config.InviteConfig
 }
 
 func New(
 	log *slog.Logger,
 	inviteRepo repository.InviteRepository,
+	contactRepo repository.ContactInviteRepository,
 	familyRepo repository.FamilyRepository,
-	manager *jwt.Manager) *InviteService {
+	joinCodeRepo repository.JoinCodeRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	sso services.SSO,
+	cfg *config.InviteConfig) *InviteService {
 	return &InviteService{
-		log:        log,
-		inviteRepo: inviteRepo,
-		familyRepo: familyRepo,
-		manager:    manager,
+		log:          log,
+		inviteRepo:   inviteRepo,
+		contactRepo:  contactRepo,
+		familyRepo:   familyRepo,
+		joinCodeRepo: joinCodeRepo,
+		outboxRepo:   outboxRepo,
+		tx:           tx,
+		sso:          sso,
+		cfg:          cfg,
 	}
 }
 
-// SendInvite allows the leader of a family to send an invitation to a user to join the family.
-// It first checks if the caller has the necessary rights to send invites (must be the leader of the family).
+// SendInvite allows the owner or a co-leader of a family to send an invitation to a user to join the family.
+// It first checks if the caller has the necessary rights to send invites (the caller's role must grant them
+// or the caller must be an admin).
 // If the caller does not have the rights, it returns a forbidden error.
 // If the user is already invited to the family, it returns an error indicating that the invite already exists.
 // If the user is already a member of the family, it returns an error indicating that the user is already in the family.
-// If all checks pass, it registers the invite in the repository and returns the invite ID.
+// If all checks pass, it registers the invite sent by the caller expiring after the configured TTL
+// in the repository and returns the invite ID. Expired invites do not count, so a user can be invited again.
 func (s *InviteService) SendInvite(
 	ctx context.Context,
 	familyID, userID int64,
@@ -47,14 +69,17 @@ func (s *InviteService) SendInvite(
 		slog.String("op", op),
 	)
 
-	clientID := s.manager.GetUserIDFromContext(ctx)
-
-	leaderID, err := s.familyRepo.GetFamilyLeaderID(ctx, familyID)
+	principal, err := jwt.PrincipalFromContext(ctx)
 	if err != nil {
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if clientID != leaderID {
+	role, err := s.familyRepo.GetMemberRole(ctx, familyID, principal.UserID)
+	if err != nil && !errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
 		log.Warn(grpcerror.ErrForbidden.Error())
 		return -1, grpcerror.ErrForbidden
 	}
@@ -79,7 +104,7 @@ func (s *InviteService) SendInvite(
 		return -1, grpcerror.ErrUserInFamily
 	}
 
-	inviteID, err := s.inviteRepo.RegisterInvite(ctx, familyID, userID)
+	inviteID, err := s.inviteRepo.RegisterInvite(ctx, familyID, userID, principal.UserID, time.Now().Add(s.cfg.TTL))
 	if err != nil {
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
@@ -87,17 +112,77 @@ func (s *InviteService) SendInvite(
 	return inviteID, nil
 }
 
-// GetInvites retrieves the invites for the current user.
-// It first retrieves the user ID from the context using the manager.
+// SendContactInvite allows the owner or a co-leader of a family to invite a person who may not be registered yet
+// by their email or phone number. The invite is stored pending, expiring after the configured TTL,
+// and turns into a regular invite once a user with that contact gets their invites.
+// It returns the ID of the pending invite. If the contact is already invited to the family,
+// it returns an error indicating that the invite already exists.
+func (s *InviteService) SendContactInvite(
+	ctx context.Context,
+	familyID int64,
+	contact models.Contact,
+) (int64, error) {
+	const op = "invite.service.SendContactInvite"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if err := contact.Validate(); err != nil {
+		log.Warn(grpcerror.ErrInvalidContact.Error(), sl.Err(err))
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
+		log.Warn(grpcerror.ErrForbidden.Error())
+		return -1, grpcerror.ErrForbidden
+	}
+
+	if role == "" {
+		// an admin outside the family, make sure the family exists
+		if _, err = s.familyRepo.GetFamilyLeaderID(ctx, familyID); err != nil {
+			return -1, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	inviteID, err := s.contactRepo.RegisterContactInvite(ctx,
+		familyID, contact.Key(), principal.UserID, time.Now().Add(s.cfg.TTL))
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return inviteID, nil
+}
+
+// GetInvites retrieves the unexpired invites for the current user.
+// It first retrieves the caller's principal from the context and turns the pending invites
+// addressed to the user's email or phone number into regular invites of the user. Resolving them
+// is best effort: if it fails, the pending invites are left for the next call and the regular ones are still returned.
 // Then, it calls the GetInvites method of the invite repository to fetch the invites associated with the user ID.
 func (s *InviteService) GetInvites(ctx context.Context) ([]*famv1.InviteModel, error) {
 	const op = "invite.service.GetInvites"
 
 	var res []*famv1.InviteModel
 
-	userID := s.manager.GetUserIDFromContext(ctx)
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = s.materializeContactInvites(ctx, principal.UserID); err != nil {
+		log.Warn("failed to resolve pending invites", sl.Err(err))
+	}
 
-	invites, err := s.inviteRepo.GetInvites(ctx, userID)
+	invites, err := s.inviteRepo.GetInvites(ctx, principal.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -111,24 +196,201 @@ func (s *InviteService) GetInvites(ctx context.Context) ([]*famv1.InviteModel, e
 	return res, nil
 }
 
+// materializeContactInvites turns the pending invites addressed to the contacts of the user into
+// regular invites of the user, keeping their inviters and expiration times. Pending invites to families
+// the user is already in or invited to, as well as to deleted families, are dropped.
+// If the contacts of the user can't be retrieved from SSO, the pending invites are left for the next call.
+func (s *InviteService) materializeContactInvites(ctx context.Context, userID int64) error {
+	const op = "invite.service.materializeContactInvites"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	user, err := s.sso.GetUserInfo(ctx, userID)
+	if err != nil {
+		log.Warn("failed to get user contacts, pending invites are not resolved", sl.Err(err))
+		return nil
+	}
+
+	contacts := models.ContactKeys(user)
+	if len(contacts) == 0 {
+		return nil
+	}
+
+	var materialized int
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		pending, err := s.contactRepo.TakeContactInvites(ctx, contacts)
+		if err != nil {
+			return err
+		}
+
+		for _, invite := range pending {
+			inFamily, err := s.familyRepo.IsUserInFamily(ctx, invite.FamilyID, userID)
+			if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			// checked beforehand, as a rejected insert aborts the whole transaction in some storages
+			invited, err := s.inviteRepo.IsUserInvited(ctx, invite.FamilyID, userID)
+			if err != nil {
+				return err
+			}
+
+			if inFamily || invited {
+				continue
+			}
+
+			_, err = s.inviteRepo.RegisterInvite(ctx, invite.FamilyID, userID, invite.InviterID, invite.ExpiresAt)
+			if err != nil {
+				return err
+			}
+
+			materialized++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if materialized > 0 {
+		log.Info("pending invites resolved",
+			slog.Int64("user_id", userID),
+			slog.Int("count", materialized))
+	}
+
+	return nil
+}
+
+// ListFamilyInvites retrieves the unexpired invites sent to the family with the given familyID,
+// both the regular ones and the pending ones addressed to contacts, which are returned separately.
+// Only those who can send invites to the family, i.e. its owner and co-leaders, and admins can list them,
+// otherwise it returns a forbidden error.
+func (s *InviteService) ListFamilyInvites(
+	ctx context.Context,
+	familyID int64,
+) ([]*famv1.InviteModel, []*famv1.PendingInviteModel, error) {
+	const op = "invite.service.ListFamilyInvites"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
+		log.Warn(grpcerror.ErrForbidden.Error())
+		return nil, nil, grpcerror.ErrForbidden
+	}
+
+	invites, err := s.inviteRepo.GetFamilyInvites(ctx, familyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	pendingInvites, err := s.contactRepo.GetFamilyContactInvites(ctx, familyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := make([]*famv1.InviteModel, 0, len(invites))
+
+	for _, invite := range invites {
+		res = append(res, models.ConvertToInviteModel(&invite))
+	}
+
+	pending := make([]*famv1.PendingInviteModel, 0, len(pendingInvites))
+
+	for _, invite := range pendingInvites {
+		pending = append(pending, models.ConvertToPendingInviteModel(&invite))
+	}
+
+	return res, pending, nil
+}
+
+// RevokeInvite withdraws the invite with the given inviteID sent to the family with the given familyID.
+// Only the leader of the family and admins can revoke invites, otherwise it returns a forbidden error.
+// If the family has no such invite, it returns ErrInviteNotFound.
+func (s *InviteService) RevokeInvite(ctx context.Context, familyID, inviteID int64) error {
+	const op = "invite.service.RevokeInvite"
+
+	if err := s.canRevokeInvites(ctx, familyID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.inviteRepo.RevokeInvite(ctx, familyID, inviteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokePendingInvite withdraws the pending invite with the given pendingInviteID addressed to a contact
+// on behalf of the family with the given familyID. Only the leader of the family and admins can revoke invites,
+// otherwise it returns a forbidden error. If the family has no such pending invite, it returns ErrInviteNotFound.
+func (s *InviteService) RevokePendingInvite(ctx context.Context, familyID, pendingInviteID int64) error {
+	const op = "invite.service.RevokePendingInvite"
+
+	if err := s.canRevokeInvites(ctx, familyID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.contactRepo.RevokeContactInvite(ctx, familyID, pendingInviteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// canRevokeInvites returns a forbidden error unless the caller is an admin or their role
+// in the family with the given familyID allows revoking invites.
+func (s *InviteService) canRevokeInvites(ctx context.Context, familyID int64) error {
+	const op = "invite.service.canRevokeInvites"
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.RevokeInvitePermission) {
+		s.log.With(slog.String("op", op)).Warn(grpcerror.ErrForbidden.Error())
+		return grpcerror.ErrForbidden
+	}
+
+	return nil
+}
+
 // AcceptInvite accepts the invite with the given inviteID for the current user.
-// It retrieves the user ID from the context using the manager, then accepts the invite
-// using the invite repository. If successful, it adds the user to the family associated
-// with the accepted invite using the family repository.
+// It retrieves the caller's principal from the context, then accepts the invite
+// using the invite repository and adds the user to the family associated with the
+// accepted invite using the family repository. Both steps run in one transaction together
+// with storing the event adding the family to the user's SSO family list, so the invite
+// is consumed only if the user actually joins the family. Expired invites are rejected with ErrInviteExpired.
 func (s *InviteService) AcceptInvite(
 	ctx context.Context,
 	inviteID int64,
 ) (int64, error) {
 	const op = "invite.service.AcceptInvite"
 
-	userID := s.manager.GetUserIDFromContext(ctx)
-
-	familyID, err := s.inviteRepo.AcceptInvite(ctx, userID, inviteID)
+	principal, err := jwt.PrincipalFromContext(ctx)
 	if err != nil {
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = s.familyRepo.AddUserToFamily(ctx, familyID, userID)
+	userID := principal.UserID
+
+	familyID, err := s.join(ctx, userID, func(ctx context.Context) (int64, error) {
+		return s.inviteRepo.AcceptInvite(ctx, userID, inviteID)
+	})
 	if err != nil {
 		return -1, fmt.Errorf("%s: %w", op, err)
 	}
@@ -136,14 +398,72 @@ func (s *InviteService) AcceptInvite(
 	return familyID, nil
 }
 
+// join adds the user to the family whose ID is returned by take and stores the event adding
+// the family to the user's SSO family list. Everything, including whatever take consumes,
+// runs in one transaction, so it is consumed only if the user actually joins the family.
+func (s *InviteService) join(
+	ctx context.Context,
+	userID int64,
+	take func(ctx context.Context) (int64, error),
+) (int64, error) {
+	var familyID int64
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		familyID, err = take(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = s.familyRepo.AddUserToFamily(ctx, familyID, userID); err != nil {
+			return err
+		}
+
+		return s.outboxRepo.AddOutboxEvents(ctx,
+			models.NewOutboxEvent(models.AddFamilyAction, userID, familyID))
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return familyID, nil
+}
+
 // DenyInvite denies the invite with the given inviteID for the current user.
 func (s *InviteService) DenyInvite(ctx context.Context, inviteID int64) error {
-	userID := s.manager.GetUserIDFromContext(ctx)
+	const op = "invite.service.DenyInvite"
 
-	return s.inviteRepo.DenyInvite(ctx, userID, inviteID)
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.inviteRepo.DenyInvite(ctx, principal.UserID, inviteID)
 }
 
 // DeleteUserInvites deletes all invites associated with the specified userID.
 func (s *InviteService) DeleteUserInvites(ctx context.Context, userID int64) error {
 	return s.inviteRepo.DeleteUserInvites(ctx, userID)
 }
+
+// callerRole returns the principal of the caller and their role in the family.
+// The role is empty if the caller is not a member of the family, so it grants no permissions.
+func (s *InviteService) callerRole(
+	ctx context.Context,
+	familyID int64,
+) (jwt.Principal, models.FamilyRole, error) {
+	const op = "invite.service.callerRole"
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return jwt.Principal{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, err := s.familyRepo.GetMemberRole(ctx, familyID, principal.UserID)
+	if err != nil && !errors.Is(err, grpcerror.ErrUserNotInFamily) {
+		return jwt.Principal{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return principal, role, nil
+}
@@ -0,0 +1,166 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/joincode"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
+	"log/slog"
+	"time"
+)
+
+// createAttempts is the number of codes generated before giving up on a collision with stored codes.
+// With 60 random bits per code a single collision is already unlikely.
+const createAttempts = 3
+
+// CreateJoinCode generates a join code for the family with the given familyID. The code can be
+// redeemed up to maxUses times, or any number of times if maxUses is zero, until expiresAt,
+// or forever if expiresAt is zero. Only those who can send invites to the family and admins
+// can create codes, otherwise it returns a forbidden error.
+func (s *InviteService) CreateJoinCode(
+	ctx context.Context,
+	familyID int64,
+	maxUses int,
+	expiresAt time.Time,
+) (models.JoinCode, error) {
+	const op = "invite.service.CreateJoinCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	now := time.Now()
+
+	if maxUses < 0 {
+		log.Warn(grpcerror.ErrInvalidJoinCode.Error(), slog.Int("max_uses", maxUses))
+		return models.JoinCode{}, fmt.Errorf("%s: %w: max_uses must not be negative", op, grpcerror.ErrInvalidJoinCode)
+	}
+
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		log.Warn(grpcerror.ErrInvalidJoinCode.Error(), slog.Time("expires_at", expiresAt))
+		return models.JoinCode{}, fmt.Errorf("%s: %w: expires_at is in the past", op, grpcerror.ErrInvalidJoinCode)
+	}
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return models.JoinCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
+		log.Warn(grpcerror.ErrForbidden.Error())
+		return models.JoinCode{}, grpcerror.ErrForbidden
+	}
+
+	if role == "" {
+		// an admin outside the family, make sure the family exists
+		if _, err = s.familyRepo.GetFamilyLeaderID(ctx, familyID); err != nil {
+			return models.JoinCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	code := models.JoinCode{
+		FamilyID:  familyID,
+		CreatedBy: principal.UserID,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	for attempt := 1; ; attempt++ {
+		code.Code, err = joincode.Generate()
+		if err != nil {
+			return models.JoinCode{}, fmt.Errorf("%s: failed to generate code: %w", op, err)
+		}
+
+		err = s.joinCodeRepo.CreateJoinCode(ctx, code)
+		if errors.Is(err, grpcerror.ErrJoinCodeExist) && attempt < createAttempts {
+			continue
+		}
+		if err != nil {
+			return models.JoinCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return code, nil
+	}
+}
+
+// ListJoinCodes retrieves the active join codes of the family with the given familyID.
+// Only those who can send invites to the family and admins can list them, otherwise it returns a forbidden error.
+func (s *InviteService) ListJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error) {
+	const op = "invite.service.ListJoinCodes"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
+		log.Warn(grpcerror.ErrForbidden.Error())
+		return nil, grpcerror.ErrForbidden
+	}
+
+	codes, err := s.joinCodeRepo.GetFamilyJoinCodes(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// DisableJoinCode disables the join code of the family with the given familyID, so it can no longer be redeemed.
+// Only those who can send invites to the family and admins can disable codes, otherwise it returns a forbidden error.
+// If the family has no such code, it returns ErrJoinCodeNotFound.
+func (s *InviteService) DisableJoinCode(ctx context.Context, familyID int64, code string) error {
+	const op = "invite.service.DisableJoinCode"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	principal, role, err := s.callerRole(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !principal.IsAdmin() && !role.Can(models.InvitePermission) {
+		log.Warn(grpcerror.ErrForbidden.Error())
+		return grpcerror.ErrForbidden
+	}
+
+	if err = s.joinCodeRepo.DisableJoinCode(ctx, familyID, joincode.Normalize(code)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// JoinByCode redeems the join code for the current user and adds them to the family of the code
+// the same way accepting an invite does: the use of the code, the membership and the event adding
+// the family to the user's SSO family list are stored in one transaction, so a use is counted only
+// if the user actually joins. Unknown, disabled, expired and used up codes are rejected with ErrJoinCodeNotFound.
+func (s *InviteService) JoinByCode(ctx context.Context, code string) (int64, error) {
+	const op = "invite.service.JoinByCode"
+
+	principal, err := jwt.PrincipalFromContext(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	code = joincode.Normalize(code)
+
+	familyID, err := s.join(ctx, principal.UserID, func(ctx context.Context) (int64, error) {
+		return s.joinCodeRepo.RedeemJoinCode(ctx, code)
+	})
+	if err != nil {
+		return -1, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return familyID, nil
+}
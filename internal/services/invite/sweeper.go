@@ -0,0 +1,86 @@
+package invite
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sweeper periodically removes expired invites from storages which do not expire documents by themselves.
+type Sweeper struct {
+	log      *slog.Logger
+	repo     repository.InviteRepository
+	interval time.Duration
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewSweeper(
+	log *slog.Logger,
+	repo repository.InviteRepository,
+	interval time.Duration,
+) *Sweeper {
+	return &Sweeper{
+		log:      log,
+		repo:     repo,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Run deletes expired invites every interval until Stop is called.
+// If the interval is zero, sweeping is disabled and Run only waits for Stop.
+func (s *Sweeper) Run() {
+	const op = "invite.sweeper.Run"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	defer close(s.done)
+
+	if s.interval <= 0 {
+		log.Info("sweeping expired invites is disabled")
+		<-s.stop
+		return
+	}
+
+	log.Info("invite sweeper is running", slog.Duration("interval", s.interval))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := s.repo.DeleteExpiredInvites(context.Background(), time.Now())
+		if err != nil {
+			log.Error("failed to delete expired invites", sl.Err(err))
+			continue
+		}
+
+		if deleted > 0 {
+			log.Info("expired invites deleted", slog.Int64("count", deleted))
+		}
+	}
+}
+
+// Stop signals the sweeper to finish and waits until the current sweep is over.
+// It may be called more than once.
+func (s *Sweeper) Stop() {
+	const op = "invite.sweeper.Stop"
+
+	s.log.With(slog.String("op", op)).Info("stopping invite sweeper")
+
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
@@ -0,0 +1,220 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Dispatcher delivers outbox events to the SSO service. Events which failed to be
+// delivered are retried with exponential backoff. An event is dead-lettered, i.e. kept
+// but no longer delivered, once the SSO service rejects it with an error retrying can't fix
+// or after MaxAttempts failed attempts; the reconciler repairs the family lists it leaves behind.
+// Delivery is at-least-once: an event may be sent again if the service stops
+// between the SSO call and the removal of the event. Every event is leased to one dispatcher
+// while it is delivered, so instances of the service sharing the outbox don't send it twice;
+// a lease left by a stopped instance expires after Lease. Events of the same user and family
+// are delivered one at a time in the order they were stored: while an event is retried,
+// the later ones wait, so a retried addition can't undo a later removal.
+type Dispatcher struct {
+	log      *slog.Logger
+	repo     repository.OutboxRepository
+	sso      services.SSO
+	cfg      *config.OutboxConfig
+	owner    string
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func New(
+	log *slog.Logger,
+	repo repository.OutboxRepository,
+	sso services.SSO,
+	cfg *config.OutboxConfig,
+) *Dispatcher {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return &Dispatcher{
+		log:   log,
+		repo:  repo,
+		sso:   sso,
+		cfg:   cfg,
+		owner: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Run polls the outbox every PollInterval and delivers due events until Stop is called.
+func (d *Dispatcher) Run() {
+	const op = "outbox.Dispatcher.Run"
+
+	log := d.log.With(
+		slog.String("op", op),
+	)
+
+	defer close(d.done)
+
+	log.Info("outbox dispatcher is running",
+		slog.Duration("poll_interval", d.cfg.PollInterval))
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch()
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop signals the dispatcher to finish and waits until the current batch is processed.
+// It may be called more than once.
+func (d *Dispatcher) Stop() {
+	const op = "outbox.Dispatcher.Stop"
+
+	d.log.With(slog.String("op", op)).Info("stopping outbox dispatcher")
+
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-d.done
+}
+
+// dispatch claims and delivers up to BatchSize due events.
+func (d *Dispatcher) dispatch() {
+	const op = "outbox.Dispatcher.dispatch"
+
+	log := d.log.With(
+		slog.String("op", op),
+	)
+
+	for i := 0; i < d.cfg.BatchSize; i++ {
+		event, ok, err := d.repo.ClaimOutboxEvent(context.Background(), d.owner, time.Now().UTC(), d.cfg.Lease)
+		if err != nil {
+			log.Error("failed to claim outbox event", sl.Err(err))
+			return
+		}
+		if !ok {
+			return
+		}
+
+		d.process(event)
+	}
+}
+
+// process delivers the claimed event and then removes, reschedules or dead-letters it.
+// The delivery has to finish before the lease expires, so it is bounded by Lease.
+func (d *Dispatcher) process(event models.OutboxEvent) {
+	const op = "outbox.Dispatcher.process"
+
+	log := d.log.With(
+		slog.String("op", op),
+		slog.Int64("event_id", event.ID),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Lease)
+	defer cancel()
+
+	err := d.deliver(ctx, event)
+	if err == nil {
+		if err = d.repo.DeleteOutboxEvent(context.Background(), event.ID); err != nil {
+			log.Error("failed to delete delivered outbox event", sl.Err(err))
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+
+	if !retryable(err) || attempts >= d.cfg.MaxAttempts {
+		log.Error("failed to deliver outbox event, dead-lettering it", sl.Err(err),
+			slog.Int("attempts", attempts))
+
+		err = d.repo.DeadLetterOutboxEvent(context.Background(), event.ID, d.owner, attempts, err.Error())
+		if err != nil {
+			log.Error("failed to dead-letter outbox event", sl.Err(err))
+		}
+		return
+	}
+
+	next := time.Now().UTC().Add(d.backoff(attempts))
+
+	log.Warn("failed to deliver outbox event", sl.Err(err),
+		slog.Int("attempts", attempts),
+		slog.Time("next_attempt_at", next))
+
+	if err = d.repo.RescheduleOutboxEvent(context.Background(), event.ID, d.owner, attempts, next); err != nil {
+		log.Error("failed to reschedule outbox event", sl.Err(err))
+	}
+}
+
+// retryable reports whether a delivery which failed with err may succeed if it is repeated.
+// Errors without a gRPC status, such as network failures, are retried.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.NotFound, codes.AlreadyExists, codes.InvalidArgument, codes.FailedPrecondition,
+		codes.OutOfRange, codes.PermissionDenied, codes.Unauthenticated, codes.Unimplemented:
+		return false
+	default:
+		return true
+	}
+}
+
+// deliver applies the event to the SSO service. Responses telling that the family list
+// is already in the desired state are treated as a successful delivery.
+func (d *Dispatcher) deliver(ctx context.Context, event models.OutboxEvent) error {
+	var err error
+
+	switch event.Action {
+	case models.AddFamilyAction:
+		err = d.sso.AddFamilyToList(ctx, event.UserID, event.FamilyID)
+		if status.Code(err) == codes.AlreadyExists {
+			return nil
+		}
+	case models.RemoveFamilyAction:
+		err = d.sso.RemoveFamilyFromList(ctx, event.UserID, event.FamilyID)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+	default:
+		d.log.Error("unknown outbox action, dropping event",
+			slog.String("action", string(event.Action)),
+			slog.Int64("event_id", event.ID))
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to %s (user_id: %d, family_id: %d): %w",
+			event.Action, event.UserID, event.FamilyID, err)
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the given attempt: MinBackoff doubled for every
+// previous failure and capped by MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.MinBackoff
+
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, d.cfg.MaxBackoff)
+}
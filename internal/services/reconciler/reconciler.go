@@ -0,0 +1,381 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
+	"golang.org/x/sync/errgroup"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+type ReconcilerService struct {
+	log        *slog.Logger
+	familyRepo repository.FamilyRepository
+	outboxRepo repository.OutboxRepository
+	formerRepo repository.FormerMemberRepository
+	tx         repository.Transactor
+	sso        services.SSO
+	cfg        *config.ReconcilerConfig
+	mu         sync.Mutex
+	stopOnce   sync.Once
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func New(
+	log *slog.Logger,
+	familyRepo repository.FamilyRepository,
+	outboxRepo repository.OutboxRepository,
+	formerRepo repository.FormerMemberRepository,
+	tx repository.Transactor,
+	sso services.SSO,
+	cfg *config.ReconcilerConfig,
+) *ReconcilerService {
+	return &ReconcilerService{
+		log:        log,
+		familyRepo: familyRepo,
+		outboxRepo: outboxRepo,
+		formerRepo: formerRepo,
+		tx:         tx,
+		sso:        sso,
+		cfg:        cfg,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run reconciles family membership with SSO family lists every Interval until Stop is called.
+// If Interval is zero, periodic reconciliation is disabled and Run only waits for Stop.
+func (s *ReconcilerService) Run() {
+	const op = "reconciler.service.Run"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	defer close(s.done)
+
+	if s.cfg.Interval <= 0 {
+		log.Info("periodic reconciliation is disabled")
+		<-s.stop
+		return
+	}
+
+	log.Info("reconciler is running", slog.Duration("interval", s.cfg.Interval))
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		report, err := s.Reconcile(context.Background(), s.cfg.DryRun, nil)
+		if err != nil {
+			log.Error("reconciliation failed", sl.Err(err))
+			continue
+		}
+
+		log.Info("reconciliation finished",
+			slog.Bool("dry_run", report.DryRun),
+			slog.Int("users_checked", report.UsersChecked),
+			slog.Int("missing", len(report.Missing)),
+			slog.Int("stale", len(report.Stale)),
+			slog.Int("failed", len(report.FailedUsersID)))
+	}
+}
+
+// Stop signals the reconciler to finish and waits until the current run is over.
+// It may be called more than once.
+func (s *ReconcilerService) Stop() {
+	const op = "reconciler.service.Stop"
+
+	s.log.With(slog.String("op", op)).Info("stopping reconciler")
+
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// Reconcile compares the membership of all families with the family lists of users in the SSO service.
+// If userIDs is empty, every member of every family and every recorded former member is checked,
+// so users who are left in no family still have deleted families removed from their lists.
+// Otherwise only the given users are checked.
+// Families are read in pages of PageSize and the users of a page are checked with at most Concurrency
+// SSO calls running in parallel. Unless dryRun is set, the drift found in a page is repaired through
+// the outbox before the next page is read: missing families are added to SSO lists and stale ones are removed.
+// Each drift is checked against the repository once more in the transaction storing its repair,
+// so changes made during the run are not reverted. Former members whose lists were checked
+// are forgotten afterwards, unless they left a family again during the run.
+func (s *ReconcilerService) Reconcile(
+	ctx context.Context,
+	dryRun bool,
+	userIDs []int64,
+) (*models.ReconcileReport, error) {
+	const op = "reconciler.service.Reconcile"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	startedAt := time.Now()
+
+	report := &models.ReconcileReport{
+		DryRun: dryRun,
+	}
+
+	checked := make(map[int64]bool)
+	families := make(map[int64]bool)
+
+	// check reconciles the users which have not been checked yet.
+	check := func(userIDs []int64) error {
+		var unchecked []int64
+
+		for _, userID := range userIDs {
+			if !checked[userID] {
+				checked[userID] = true
+				unchecked = append(unchecked, userID)
+			}
+		}
+
+		slices.Sort(unchecked)
+
+		return s.reconcileUsers(ctx, unchecked, report, families)
+	}
+
+	if len(userIDs) > 0 {
+		if err := check(userIDs); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	} else {
+		var afterID int64
+
+		for {
+			page, err := s.familyRepo.ListFamilies(ctx, afterID, s.pageSize())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+
+			var members []int64
+			for _, family := range page {
+				members = append(members, family.MembersID...)
+			}
+
+			if err = check(members); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if len(page) < s.pageSize() {
+				break
+			}
+			afterID = page[len(page)-1].ID
+		}
+
+		formers, err := s.formerRepo.GetFormerMembers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		formerIDs := make([]int64, 0, len(formers))
+		for _, former := range formers {
+			formerIDs = append(formerIDs, former.UserID)
+		}
+
+		if err = check(formerIDs); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	report.FamiliesChecked = len(families)
+	report.UsersChecked = len(checked)
+
+	if dryRun {
+		return report, nil
+	}
+
+	for userID := range checked {
+		if slices.Contains(report.FailedUsersID, userID) {
+			continue
+		}
+
+		if err := s.formerRepo.DeleteFormerMembers(ctx, userID, startedAt); err != nil {
+			log.Warn("failed to forget former memberships", sl.Err(err), slog.Int64("user_id", userID))
+		}
+	}
+
+	return report, nil
+}
+
+// userDrift is the result of comparing the families of a user with the family list of the user in SSO.
+type userDrift struct {
+	families []int64
+	missing  []models.FamilyListDrift
+	stale    []models.FamilyListDrift
+	err      error
+}
+
+// reconcileUsers compares the family lists of the users with their membership, running at most Concurrency
+// SSO calls in parallel, adds the results to the report in the order of userIDs and, unless the report
+// is a dry run, repairs the drift found. The IDs of the families of the users are added to families.
+// Users whose lists could not be retrieved are reported as failed.
+func (s *ReconcilerService) reconcileUsers(
+	ctx context.Context,
+	userIDs []int64,
+	report *models.ReconcileReport,
+	families map[int64]bool,
+) error {
+	const op = "reconciler.service.reconcileUsers"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	drifts := make([]userDrift, len(userIDs))
+
+	var g errgroup.Group
+
+	g.SetLimit(max(s.cfg.Concurrency, 1))
+
+	for i, userID := range userIDs {
+		i, userID := i, userID
+
+		g.Go(func() error {
+			drifts[i] = s.compare(ctx, userID)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	var missing, stale []models.FamilyListDrift
+
+	for i, drift := range drifts {
+		if drift.err != nil {
+			log.Warn("failed to compare user's family list", sl.Err(drift.err), slog.Int64("user_id", userIDs[i]))
+			report.FailedUsersID = append(report.FailedUsersID, userIDs[i])
+			continue
+		}
+
+		for _, familyID := range drift.families {
+			families[familyID] = true
+		}
+
+		missing = append(missing, drift.missing...)
+		stale = append(stale, drift.stale...)
+	}
+
+	report.Missing = append(report.Missing, missing...)
+	report.Stale = append(report.Stale, stale...)
+
+	if report.DryRun || len(missing)+len(stale) == 0 {
+		return nil
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.repair(ctx, missing, stale)
+	})
+}
+
+// compare retrieves the families of the user from the repository and the family list of the user from SSO
+// and returns the families missing from the list and the stale ones.
+func (s *ReconcilerService) compare(ctx context.Context, userID int64) userDrift {
+	var (
+		drift   userDrift
+		afterID int64
+	)
+
+	for {
+		page, err := s.familyRepo.ListUserFamilies(ctx, userID, afterID, s.pageSize())
+		if err != nil {
+			return userDrift{err: err}
+		}
+
+		for _, family := range page {
+			drift.families = append(drift.families, family.ID)
+		}
+
+		if len(page) < s.pageSize() {
+			break
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	listed, err := s.sso.GetUserFamilies(ctx, userID)
+	if err != nil {
+		return userDrift{err: err}
+	}
+
+	for _, familyID := range drift.families {
+		if !slices.Contains(listed, familyID) {
+			drift.missing = append(drift.missing, models.FamilyListDrift{UserID: userID, FamilyID: familyID})
+		}
+	}
+
+	for _, familyID := range listed {
+		if !slices.Contains(drift.families, familyID) {
+			drift.stale = append(drift.stale, models.FamilyListDrift{UserID: userID, FamilyID: familyID})
+		}
+	}
+
+	return drift
+}
+
+// repair stores outbox events fixing the drift which is still present in the repository.
+// It has to be called within a transaction, so the membership checked is the one the events are stored with.
+func (s *ReconcilerService) repair(ctx context.Context, missing, stale []models.FamilyListDrift) error {
+	events := make([]models.OutboxEvent, 0, len(missing)+len(stale))
+
+	for _, drift := range missing {
+		inFamily, err := s.isMember(ctx, drift)
+		if err != nil {
+			return err
+		}
+
+		if inFamily {
+			events = append(events,
+				models.NewOutboxEvent(models.AddFamilyAction, drift.UserID, drift.FamilyID))
+		}
+	}
+
+	for _, drift := range stale {
+		inFamily, err := s.isMember(ctx, drift)
+		if err != nil {
+			return err
+		}
+
+		if !inFamily {
+			events = append(events,
+				models.NewOutboxEvent(models.RemoveFamilyAction, drift.UserID, drift.FamilyID))
+		}
+	}
+
+	return s.outboxRepo.AddOutboxEvents(ctx, events...)
+}
+
+// pageSize returns the number of families read at once, which is at least one.
+func (s *ReconcilerService) pageSize() int {
+	return max(s.cfg.PageSize, 1)
+}
+
+func (s *ReconcilerService) isMember(ctx context.Context, drift models.FamilyListDrift) (bool, error) {
+	inFamily, err := s.familyRepo.IsUserInFamily(ctx, drift.FamilyID, drift.UserID)
+	if errors.Is(err, grpcerror.ErrFamilyNotFound) {
+		return false, nil
+	}
+
+	return inFamily, err
+}
@@ -0,0 +1,84 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/repository"
+	"log/slog"
+	"time"
+)
+
+type RevocationService struct {
+	log  *slog.Logger
+	repo repository.RevocationRepository
+	cfg  *config.RevocationConfig
+}
+
+func New(
+	log *slog.Logger,
+	repo repository.RevocationRepository,
+	cfg *config.RevocationConfig,
+) *RevocationService {
+	return &RevocationService{
+		log:  log,
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// RevokeToken revokes the token with the given jti. If the expiry of the token is unknown,
+// the revocation is kept for TokenTTL, which should not be shorter than the lifetime of tokens.
+func (s *RevocationService) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const op = "revocation.service.RevokeToken"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(s.cfg.TokenTTL)
+	}
+
+	err := s.repo.RevokeToken(ctx, jti, expiresAt)
+	if err != nil {
+		log.Error("failed to revoke token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("token revoked", slog.String("jti", jti))
+
+	return nil
+}
+
+// RevokeUserTokens revokes all tokens of the user issued before issuedBefore.
+// A zero issuedBefore revokes all tokens issued up to now. Issue times of tokens are known
+// to the second, so issuedBefore is rounded up to a whole second: tokens issued during
+// the second of the revocation are revoked as well, even if they were issued after it.
+func (s *RevocationService) RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time) error {
+	const op = "revocation.service.RevokeUserTokens"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if issuedBefore.IsZero() {
+		issuedBefore = time.Now()
+	}
+	if second := issuedBefore.Truncate(time.Second); second.Before(issuedBefore) {
+		issuedBefore = second.Add(time.Second)
+	}
+
+	err := s.repo.RevokeUserTokens(ctx, userID, issuedBefore)
+	if err != nil {
+		log.Error("failed to revoke user's tokens", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user's tokens revoked",
+		slog.Int64("user_id", userID),
+		slog.Time("issued_before", issuedBefore))
+
+	return nil
+}
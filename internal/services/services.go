@@ -4,29 +4,75 @@ import (
 	"context"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
 	famv1 "github.com/Stanislau-Senkevich/protocols/gen/go/family"
+	"time"
 )
 
 type SSO interface {
-	GetUserInfo(userID int64) (*models.User, error)
-	AddFamilyToList(ctx context.Context, familyID int64) error
-	RemoveFamilyFromList(userID, familyID int64) error
+	GetUserInfo(ctx context.Context, userID int64) (*models.User, error)
+	GetUsersInfo(ctx context.Context, userIDs []int64) (users []*models.User, notFound, failed []int64)
+	GetUserFamilies(ctx context.Context, userID int64) ([]int64, error)
+	AddFamilyToList(ctx context.Context, userID, familyID int64) error
+	RemoveFamilyFromList(ctx context.Context, userID, familyID int64) error
+}
+
+type UserCache interface {
+	InvalidateUser(userID int64)
+	Stats() models.CacheStats
 }
 
 type Family interface {
-	CreateFamily(ctx context.Context) (int64, error)
-	GetFamilyMembersIDs(ctx context.Context, familyID int64) ([]int64, error)
+	CreateFamily(ctx context.Context, profile models.FamilyProfile) (models.Family, error)
+	GetFamily(ctx context.Context, familyID int64) (models.Family, error)
+	ListMyFamilies(ctx context.Context, cursor string, limit int) ([]models.FamilySummary, string, error)
 	LeaveFamily(ctx context.Context, familyID int64) (int64, error)
 }
 
 type FamilyLeader interface {
 	RemoveUserFromFamily(ctx context.Context, familyID, userID int64) error
 	DeleteFamily(ctx context.Context, familyID int64) ([]int64, error)
+	SetMemberRole(ctx context.Context, familyID, userID int64, role models.FamilyRole) error
+	TransferLeadership(ctx context.Context, familyID, userID int64) error
+	SetSuccessionPolicy(ctx context.Context, familyID int64, policy models.SuccessionPolicy) error
+	UpdateFamily(ctx context.Context, familyID int64, update models.FamilyUpdate) (models.Family, error)
+}
+
+type Succession interface {
+	Successor(ctx context.Context, family models.Family, leavingUserID int64) (int64, error)
+}
+
+type Reconciler interface {
+	Reconcile(ctx context.Context, dryRun bool, userIDs []int64) (*models.ReconcileReport, error)
+}
+
+type FamilySearch interface {
+	SearchFamilies(
+		ctx context.Context,
+		filter models.FamilyFilter,
+		cursor string,
+		limit int,
+	) ([]models.Family, string, error)
+}
+
+type Revocation interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID int64, issuedBefore time.Time) error
+}
+
+type JoinCode interface {
+	CreateJoinCode(ctx context.Context, familyID int64, maxUses int, expiresAt time.Time) (models.JoinCode, error)
+	ListJoinCodes(ctx context.Context, familyID int64) ([]models.JoinCode, error)
+	DisableJoinCode(ctx context.Context, familyID int64, code string) error
+	JoinByCode(ctx context.Context, code string) (int64, error)
 }
 
 type Invite interface {
 	SendInvite(ctx context.Context, familyID, userID int64) (int64, error)
+	SendContactInvite(ctx context.Context, familyID int64, contact models.Contact) (int64, error)
 	GetInvites(ctx context.Context) ([]*famv1.InviteModel, error)
+	ListFamilyInvites(ctx context.Context, familyID int64) ([]*famv1.InviteModel, []*famv1.PendingInviteModel, error)
 	AcceptInvite(ctx context.Context, inviteID int64) (int64, error)
 	DenyInvite(ctx context.Context, inviteID int64) error
+	RevokeInvite(ctx context.Context, familyID, inviteID int64) error
+	RevokePendingInvite(ctx context.Context, familyID, pendingInviteID int64) error
 	DeleteUserInvites(ctx context.Context, userID int64) error
 }
@@ -0,0 +1,93 @@
+package sso
+
+import (
+	"context"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// GetUsersInfo retrieves information about the given users from the SSO service.
+// It returns the users found, in the order of userIDs, the IDs of users the SSO service doesn't know
+// and the IDs of users whose information could not be retrieved. If a bulk fetcher is set, all users
+// are requested in a single call, otherwise at most concurrency GetUserInfo calls run in parallel.
+func (s *SSOService) GetUsersInfo(ctx context.Context, userIDs []int64) ([]*models.User, []int64, []int64) {
+	const op = "sso.service.GetUsersInfo"
+
+	log := s.client.Log.With(
+		slog.String("op", op),
+	)
+
+	found := make([]*models.User, len(userIDs))
+	notFound := make([]bool, len(userIDs))
+
+	if s.bulkFetch != nil {
+		users, err := s.bulkFetch(ctx, userIDs)
+		if err != nil {
+			log.Warn("failed to fetch users in bulk", sl.Err(err))
+		}
+
+		for i, userID := range userIDs {
+			found[i] = users[userID]
+			notFound[i] = err == nil && found[i] == nil
+		}
+
+		return collect(userIDs, found, notFound)
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(s.concurrency)
+
+	for i, userID := range userIDs {
+		i, userID := i, userID
+
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			user, err := s.GetUserInfo(ctx, userID)
+			if status.Code(err) == codes.NotFound {
+				notFound[i] = true
+				return nil
+			}
+			if err != nil {
+				log.Warn("user's info is missing", sl.Err(err), slog.Int64("user_id", userID))
+				return nil
+			}
+
+			found[i] = user
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return collect(userIDs, found, notFound)
+}
+
+// collect splits the lookup results into the users found, the IDs of the users which don't exist
+// and the IDs of the ones which failed to be retrieved.
+func collect(userIDs []int64, found []*models.User, notFound []bool) ([]*models.User, []int64, []int64) {
+	users := make([]*models.User, 0, len(userIDs))
+	missing := make([]int64, 0)
+	failed := make([]int64, 0)
+
+	for i, user := range found {
+		switch {
+		case user != nil:
+			users = append(users, user)
+		case notFound[i]:
+			missing = append(missing, userIDs[i])
+		default:
+			failed = append(failed, userIDs[i])
+		}
+	}
+
+	return users, missing, failed
+}
@@ -0,0 +1,304 @@
+package sso
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/config"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/cache"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedSSO is a read-through cache in front of the user profile lookups of an SSO service.
+// Profiles are kept in an LRU cache with a TTL; users reported as not found by SSO are cached
+// as negative entries with a separate, usually shorter, TTL. Concurrent lookups of the same user
+// which miss the cache share a single SSO call, whether they look up one user or a batch.
+// The cache counters are logged every StatsInterval while Run is running. All other calls are passed through.
+type CachedSSO struct {
+	services.SSO
+	log          *slog.Logger
+	users        *cache.LRU[int64, *models.User]
+	cfg          *config.UserCacheConfig
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+	mu           sync.Mutex
+	inflight     map[int64]*lookupCall
+	stopOnce     sync.Once
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// lookupCall is an SSO lookup of a user in progress. Its results may be read once done is closed.
+type lookupCall struct {
+	done     chan struct{}
+	user     *models.User
+	notFound bool
+}
+
+func NewCached(log *slog.Logger, sso services.SSO, cfg *config.UserCacheConfig) *CachedSSO {
+	return &CachedSSO{
+		SSO:      sso,
+		log:      log,
+		users:    cache.NewLRU[int64, *models.User](cfg.Size),
+		cfg:      cfg,
+		inflight: make(map[int64]*lookupCall),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// GetUserInfo returns the cached profile of the user or retrieves it from the SSO service.
+func (c *CachedSSO) GetUserInfo(ctx context.Context, userID int64) (*models.User, error) {
+	const op = "sso.cache.GetUserInfo"
+
+	if user, ok := c.lookup(userID); ok {
+		if user == nil {
+			return nil, fmt.Errorf("%s (id: %d): %w", op, userID, grpcerror.ErrUserNotFound)
+		}
+		return user, nil
+	}
+
+	calls, owned := c.join([]int64{userID})
+	if len(owned) > 0 {
+		user, err := c.SSO.GetUserInfo(ctx, userID)
+		notFound := status.Code(err) == codes.NotFound
+		c.finish(userID, user, notFound)
+
+		if notFound {
+			return nil, fmt.Errorf("%s (id: %d): %w", op, userID, grpcerror.ErrUserNotFound)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	call := calls[0]
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s (id: %d): %w", op, userID, ctx.Err())
+	}
+
+	switch {
+	case call.notFound:
+		return nil, fmt.Errorf("%s (id: %d): %w", op, userID, grpcerror.ErrUserNotFound)
+	case call.user == nil:
+		return nil, fmt.Errorf("%s (id: %d): %w", op, userID, grpcerror.ErrInternalError)
+	default:
+		return call.user, nil
+	}
+}
+
+// GetUsersInfo returns the cached profiles of the users and retrieves the missing ones
+// from the SSO service in one batch. Users being retrieved by other calls are waited for
+// instead of being requested again.
+func (c *CachedSSO) GetUsersInfo(ctx context.Context, userIDs []int64) ([]*models.User, []int64, []int64) {
+	cached := make(map[int64]*models.User, len(userIDs))
+	missing := make([]int64, 0, len(userIDs))
+	notFound := make([]int64, 0)
+	failed := make([]int64, 0)
+
+	for _, userID := range userIDs {
+		user, ok := c.lookup(userID)
+		switch {
+		case !ok:
+			missing = append(missing, userID)
+		case user == nil:
+			notFound = append(notFound, userID)
+		default:
+			cached[userID] = user
+		}
+	}
+
+	if len(missing) == 0 {
+		return c.ordered(userIDs, cached), notFound, failed
+	}
+
+	calls, owned := c.join(missing)
+
+	if len(owned) > 0 {
+		fetched, fetchNotFound, fetchFailed := c.SSO.GetUsersInfo(ctx, owned)
+
+		for _, user := range fetched {
+			c.finish(user.ID, user, false)
+		}
+		for _, userID := range fetchNotFound {
+			c.finish(userID, nil, true)
+		}
+		for _, userID := range fetchFailed {
+			c.finish(userID, nil, false)
+		}
+	}
+
+	for i, userID := range missing {
+		call := calls[i]
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			failed = append(failed, userID)
+			continue
+		}
+
+		switch {
+		case call.notFound:
+			notFound = append(notFound, userID)
+		case call.user == nil:
+			failed = append(failed, userID)
+		default:
+			cached[userID] = call.user
+		}
+	}
+
+	return c.ordered(userIDs, cached), notFound, failed
+}
+
+// ordered returns the found users in the order of userIDs.
+func (c *CachedSSO) ordered(userIDs []int64, found map[int64]*models.User) []*models.User {
+	users := make([]*models.User, 0, len(found))
+
+	for _, userID := range userIDs {
+		if user, ok := found[userID]; ok {
+			users = append(users, user)
+		}
+	}
+
+	return users
+}
+
+// join returns the lookup calls of the users, in the order of userIDs, starting the ones which are
+// not in progress yet. The IDs of the users whose calls were started are returned as well:
+// the caller has to retrieve these users and finish their calls.
+func (c *CachedSSO) join(userIDs []int64) ([]*lookupCall, []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	calls := make([]*lookupCall, len(userIDs))
+	owned := make([]int64, 0, len(userIDs))
+
+	for i, userID := range userIDs {
+		call, ok := c.inflight[userID]
+		if !ok {
+			call = &lookupCall{done: make(chan struct{})}
+			c.inflight[userID] = call
+			owned = append(owned, userID)
+		}
+
+		calls[i] = call
+	}
+
+	return calls, owned
+}
+
+// finish caches the result of the lookup of the user and passes it to the callers waiting for it.
+// A nil user which is not reported as not found is a failed lookup and is not cached.
+func (c *CachedSSO) finish(userID int64, user *models.User, notFound bool) {
+	switch {
+	case user != nil:
+		c.users.Set(userID, user, c.cfg.TTL)
+	case notFound:
+		c.users.Set(userID, nil, c.cfg.NegativeTTL)
+	}
+
+	c.mu.Lock()
+	call, ok := c.inflight[userID]
+	delete(c.inflight, userID)
+	c.mu.Unlock()
+
+	if ok {
+		call.user = user
+		call.notFound = notFound
+		close(call.done)
+	}
+}
+
+// InvalidateUser removes the cached profile of the user, so the next lookup goes to the SSO service.
+func (c *CachedSSO) InvalidateUser(userID int64) {
+	const op = "sso.cache.InvalidateUser"
+
+	c.users.Delete(userID)
+
+	c.log.With(slog.String("op", op)).
+		Info("user's cached profile invalidated", slog.Int64("user_id", userID))
+}
+
+// Stats returns the cache counters accumulated since the service start.
+func (c *CachedSSO) Stats() models.CacheStats {
+	return models.CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Size:         c.users.Len(),
+	}
+}
+
+// Run logs the cache counters every StatsInterval until Stop is called.
+// If StatsInterval is zero, the counters are only available through Stats and Run only waits for Stop.
+func (c *CachedSSO) Run() {
+	const op = "sso.cache.Run"
+
+	log := c.log.With(
+		slog.String("op", op),
+	)
+
+	defer close(c.done)
+
+	if c.cfg.StatsInterval <= 0 {
+		<-c.stop
+		return
+	}
+
+	ticker := time.NewTicker(c.cfg.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+
+		stats := c.Stats()
+
+		log.Info("user cache stats",
+			slog.Int64("hits", stats.Hits),
+			slog.Int64("negative_hits", stats.NegativeHits),
+			slog.Int64("misses", stats.Misses),
+			slog.Int("size", stats.Size),
+			slog.Float64("hit_ratio", stats.HitRatio()))
+	}
+}
+
+// Stop signals the stats logging to finish and waits for it. It may be called more than once.
+func (c *CachedSSO) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+}
+
+// lookup returns the cached entry of the user and updates the counters.
+// A nil user with ok set means the user is cached as not found.
+func (c *CachedSSO) lookup(userID int64) (*models.User, bool) {
+	user, ok := c.users.Get(userID)
+
+	switch {
+	case !ok:
+		c.misses.Add(1)
+	case user == nil:
+		c.negativeHits.Add(1)
+	default:
+		c.hits.Add(1)
+	}
+
+	return user, ok
+}
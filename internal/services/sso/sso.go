@@ -5,49 +5,60 @@ import (
 	"fmt"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/client/sso/grpc"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
-	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/jwt"
 	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
 	ssov1 "github.com/Stanislau-Senkevich/protocols/gen/go/sso"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"log/slog"
 )
 
+// BulkUserInfoFetcher retrieves information about several users in a single SSO call.
+// Users missing from the returned map are reported as not found.
+type BulkUserInfoFetcher func(ctx context.Context, userIDs []int64) (map[int64]*models.User, error)
+
 type SSOService struct {
-	client        *grpc.Client
-	manager       *jwt.Manager
-	adminEmail    string
-	adminPassword string
+	client      *grpc.Client
+	tokens      *tokenProvider
+	concurrency int
+	bulkFetch   BulkUserInfoFetcher
 }
 
 func New(
 	client *grpc.Client,
-	manager *jwt.Manager,
 	adminEmail string,
 	adminPassword string,
+	concurrency int,
 ) *SSOService {
 	return &SSOService{
-		client:        client,
-		manager:       manager,
-		adminEmail:    adminEmail,
-		adminPassword: adminPassword,
+		client:      client,
+		tokens:      newTokenProvider(client, adminEmail, adminPassword),
+		concurrency: max(concurrency, 1),
 	}
 }
 
+// SetBulkFetcher makes GetUsersInfo use the provided bulk call instead of
+// fanning out GetUserInfo calls. It should be called before the service is used.
+func (s *SSOService) SetBulkFetcher(fetch BulkUserInfoFetcher) {
+	s.bulkFetch = fetch
+}
+
 // GetUserInfo retrieves user information from the SSO service.
-func (s *SSOService) GetUserInfo(userID int64) (*models.User, error) {
+func (s *SSOService) GetUserInfo(ctx context.Context, userID int64) (*models.User, error) {
 	const op = "sso.service.GetUserInfo"
 
+	var resp *ssov1.GetUserInfoByIDResponse
+
 	log := s.client.Log.With(
 		slog.String("op", op),
 	)
 
-	ctx, err := s.signInAndGetContext()
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-
-	req, err := s.client.Userinfo.GetUserInfoByID(ctx, &ssov1.GetUserInfoByIDRequest{
-		UserId: userID,
+	err := s.withAdminContext(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.client.Userinfo.GetUserInfoByID(ctx, &ssov1.GetUserInfoByIDRequest{
+			UserId: userID,
+		})
+		return err
 	})
 	if err != nil {
 		log.Warn("failed to find user", slog.Int64("user_id", userID))
@@ -55,33 +66,54 @@ func (s *SSOService) GetUserInfo(userID int64) (*models.User, error) {
 	}
 
 	return &models.User{
-		ID:           req.GetUserId(),
-		Email:        req.GetEmail(),
-		PhoneNumber:  req.GetPhoneNumber(),
-		Name:         req.GetName(),
-		Surname:      req.GetSurname(),
-		RegisteredAt: req.GetRegisteredAt().AsTime(),
+		ID:           resp.GetUserId(),
+		Email:        resp.GetEmail(),
+		PhoneNumber:  resp.GetPhoneNumber(),
+		Name:         resp.GetName(),
+		Surname:      resp.GetSurname(),
+		RegisteredAt: resp.GetRegisteredAt().AsTime(),
 	}, nil
 }
 
-// AddFamilyToList adds a family to the user's family list in the SSO service.
-func (s *SSOService) AddFamilyToList(ctx context.Context, familyID int64) error {
-	const op = "sso.service.AddFamilyToList"
+// GetUserFamilies retrieves the IDs of families listed in the user's profile in the SSO service.
+func (s *SSOService) GetUserFamilies(ctx context.Context, userID int64) ([]int64, error) {
+	const op = "sso.service.GetUserFamilies"
+
+	var resp *ssov1.GetUserInfoByIDResponse
 
 	log := s.client.Log.With(
 		slog.String("op", op),
 	)
 
-	userID := s.manager.GetUserIDFromContext(ctx)
-
-	adminCtx, err := s.signInAndGetContext()
+	err := s.withAdminContext(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.client.Userinfo.GetUserInfoByID(ctx, &ssov1.GetUserInfoByIDRequest{
+			UserId: userID,
+		})
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		log.Warn("failed to find user", slog.Int64("user_id", userID))
+		return nil, fmt.Errorf("%s (id: %d): %w", op, userID, err)
 	}
 
-	_, err = s.client.Userinfo.AddFamily(adminCtx, &ssov1.AddFamilyRequest{
-		UserId:   userID,
-		FamilyId: familyID,
+	return resp.GetFamilyIds(), nil
+}
+
+// AddFamilyToList adds a family to the user's family list in the SSO service.
+func (s *SSOService) AddFamilyToList(ctx context.Context, userID, familyID int64) error {
+	const op = "sso.service.AddFamilyToList"
+
+	log := s.client.Log.With(
+		slog.String("op", op),
+	)
+
+	err := s.withAdminContext(ctx, func(ctx context.Context) error {
+		_, err := s.client.Userinfo.AddFamily(ctx, &ssov1.AddFamilyRequest{
+			UserId:   userID,
+			FamilyId: familyID,
+		})
+		return err
 	})
 	if err != nil {
 		log.Warn("failed to add family", sl.Err(err),
@@ -93,21 +125,19 @@ func (s *SSOService) AddFamilyToList(ctx context.Context, familyID int64) error
 }
 
 // RemoveFamilyFromList removes a family from the user's family list in the SSO service.
-func (s *SSOService) RemoveFamilyFromList(userID, familyID int64) error {
+func (s *SSOService) RemoveFamilyFromList(ctx context.Context, userID, familyID int64) error {
 	const op = "sso.service.RemoveFamilyFromList"
 
 	log := s.client.Log.With(
 		slog.String("op", op),
 	)
 
-	adminCtx, err := s.signInAndGetContext()
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	_, err = s.client.Userinfo.DeleteFamily(adminCtx, &ssov1.DeleteFamilyRequest{
-		UserId:   userID,
-		FamilyId: familyID,
+	err := s.withAdminContext(ctx, func(ctx context.Context) error {
+		_, err := s.client.Userinfo.DeleteFamily(ctx, &ssov1.DeleteFamilyRequest{
+			UserId:   userID,
+			FamilyId: familyID,
+		})
+		return err
 	})
 	if err != nil {
 		log.Warn("failed to delete family", sl.Err(err),
@@ -118,26 +148,35 @@ func (s *SSOService) RemoveFamilyFromList(userID, familyID int64) error {
 	return nil
 }
 
-func (s *SSOService) signInAndGetContext() (context.Context, error) {
-	const op = "sso.signInAndGetContext"
+// withAdminContext calls fn with ctx authorized by the cached admin token.
+// If the SSO service rejects the token as unauthenticated, the token is refreshed
+// and fn is retried once.
+func (s *SSOService) withAdminContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "sso.withAdminContext"
 
-	log := s.client.Log.With(
-		slog.String("op", op),
-	)
-
-	respSign, err := s.client.Auth.SignIn(context.Background(),
-		&ssov1.SignInRequest{
-			Email:    s.adminEmail,
-			Password: s.adminPassword,
-		})
+	token, err := s.tokens.Token()
 	if err != nil {
-		log.Error("failed to sign in to sso", sl.Err(err))
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	token := "Bearer " + respSign.GetToken()
+	err = fn(adminContext(ctx, token))
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+
+	s.client.Log.With(slog.String("op", op)).
+		Info("admin token was rejected, signing in again")
+
+	s.tokens.Invalidate(token)
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+	token, err = s.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fn(adminContext(ctx, token))
+}
 
-	return ctx, nil
+func adminContext(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
 }
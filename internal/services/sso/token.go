@@ -0,0 +1,121 @@
+package sso
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/client/sso/grpc"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/lib/sl"
+	ssov1 "github.com/Stanislau-Senkevich/protocols/gen/go/sso"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/sync/singleflight"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenRefreshMargin is how long before its expiry the cached token is refreshed.
+	tokenRefreshMargin = 30 * time.Second
+	// defaultTokenTTL is used for tokens which do not have the exp claim.
+	defaultTokenTTL = 5 * time.Minute
+)
+
+// tokenProvider caches the admin token issued by the SSO service and signs in again
+// only when the token is about to expire or has been rejected. Concurrent refreshes
+// are deduplicated, so at most one SignIn call is in flight.
+type tokenProvider struct {
+	client    *grpc.Client
+	email     string
+	password  string
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	group     singleflight.Group
+}
+
+func newTokenProvider(client *grpc.Client, email, password string) *tokenProvider {
+	return &tokenProvider{
+		client:   client,
+		email:    email,
+		password: password,
+	}
+}
+
+// Token returns the cached admin token, refreshing it if it expires within tokenRefreshMargin.
+func (p *tokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	token, expiresAt := p.token, p.expiresAt
+	p.mu.Unlock()
+
+	if token != "" && time.Now().Add(tokenRefreshMargin).Before(expiresAt) {
+		return token, nil
+	}
+
+	res, err, _ := p.group.Do("token", func() (interface{}, error) {
+		return p.refresh()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, _ = res.(string)
+
+	return token, nil
+}
+
+// Invalidate drops the cached token if it is still the given one, so the next call to Token signs in again.
+func (p *tokenProvider) Invalidate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == token {
+		p.token = ""
+		p.expiresAt = time.Time{}
+	}
+}
+
+// refresh signs in to the SSO service and caches the issued token until its exp claim.
+func (p *tokenProvider) refresh() (string, error) {
+	const op = "sso.tokenProvider.refresh"
+
+	log := p.client.Log.With(
+		slog.String("op", op),
+	)
+
+	resp, err := p.client.Auth.SignIn(context.Background(),
+		&ssov1.SignInRequest{
+			Email:    p.email,
+			Password: p.password,
+		})
+	if err != nil {
+		log.Error("failed to sign in to sso", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token := resp.GetToken()
+	expiresAt := tokenExpiry(token)
+
+	log.Debug("admin token refreshed", slog.Time("expires_at", expiresAt))
+
+	p.mu.Lock()
+	p.token = token
+	p.expiresAt = expiresAt
+	p.mu.Unlock()
+
+	return token, nil
+}
+
+// tokenExpiry reads the exp claim of the token without verifying its signature,
+// since the token was just received from the SSO service directly. If the claim is
+// missing or the token cannot be parsed, defaultTokenTTL is used.
+func tokenExpiry(token string) time.Time {
+	claims := jwt.MapClaims{}
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err == nil {
+		if exp, ok := claims["exp"].(float64); ok {
+			return time.Unix(int64(exp), 0)
+		}
+	}
+
+	return time.Now().Add(defaultTokenTTL)
+}
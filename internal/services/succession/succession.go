@@ -0,0 +1,105 @@
+package succession
+
+import (
+	"context"
+	"fmt"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/domain/models"
+	grpcerror "github.com/Stanislau-Senkevich/GRPC_Family/internal/error"
+	"github.com/Stanislau-Senkevich/GRPC_Family/internal/services"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Planner chooses the next leader of a family according to its succession policy.
+type Planner struct {
+	log *slog.Logger
+	sso services.SSO
+}
+
+func New(log *slog.Logger, sso services.SSO) *Planner {
+	return &Planner{
+		log: log,
+		sso: sso,
+	}
+}
+
+// Successor returns the member who takes over the leadership when the user leaves the family.
+// It returns 0 if no transfer is needed: the user is not the leader or is the last member.
+// If the family requires an explicit transfer, it returns ErrTransferRequired.
+// The family has to be read in the transaction which transfers the leadership, so the successor
+// is still a member when it takes over.
+func (p *Planner) Successor(ctx context.Context, family models.Family, leavingUserID int64) (int64, error) {
+	const op = "succession.Successor"
+
+	log := p.log.With(
+		slog.String("op", op),
+	)
+
+	if family.LeaderUserID != leavingUserID {
+		return 0, nil
+	}
+
+	candidates := byTenure(family, leavingUserID)
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	switch family.Succession() {
+	case models.RequireTransferSuccession:
+		log.Warn(grpcerror.ErrTransferRequired.Error(), slog.Int64("family_id", family.ID))
+		return 0, fmt.Errorf("%s: %w", op, grpcerror.ErrTransferRequired)
+	case models.OldestMemberSuccession:
+		return p.oldestMember(ctx, candidates), nil
+	default:
+		return candidates[0], nil
+	}
+}
+
+// byTenure returns the members of the family except the leaving user, ordered by the time they joined
+// the family. Members who joined before join times were recorded come first; members who joined
+// at the same time keep the order of the members list.
+func byTenure(family models.Family, leavingUserID int64) []int64 {
+	joinedAt := make(map[int64]time.Time, len(family.Members))
+	for _, member := range family.Members {
+		joinedAt[member.UserID] = member.JoinedAt
+	}
+
+	candidates := slices.DeleteFunc(slices.Clone(family.MembersID), func(id int64) bool {
+		return id == leavingUserID
+	})
+
+	slices.SortStableFunc(candidates, func(a, b int64) int {
+		return joinedAt[a].Compare(joinedAt[b])
+	})
+
+	return candidates
+}
+
+// oldestMember returns the candidate whose SSO account was registered first. Candidates with equally old
+// accounts are ordered by tenure. Candidates whose profiles couldn't be fetched are skipped;
+// if none could be fetched, the longest-tenured candidate is returned.
+// The candidates must be ordered by tenure.
+func (p *Planner) oldestMember(ctx context.Context, candidates []int64) int64 {
+	const op = "succession.oldestMember"
+
+	users, notFound, failed := p.sso.GetUsersInfo(ctx, candidates)
+	failed = append(notFound, failed...)
+	if len(failed) > 0 {
+		p.log.With(slog.String("op", op)).
+			Warn("failed to fetch some candidates", slog.Any("user_ids", failed))
+	}
+
+	if len(users) == 0 {
+		return candidates[0]
+	}
+
+	oldest := users[0]
+	for _, user := range users[1:] {
+		if user.RegisteredAt.Before(oldest.RegisteredAt) {
+			oldest = user
+		}
+	}
+
+	return oldest.ID
+}